@@ -0,0 +1,140 @@
+// Command apexscan finds the inline SOQL and SOSL queries in the Apex source files
+// (*.cls and *.trigger) and reports the parse result of each query.
+//
+// Usage:
+//
+//	apexscan [-json] [-errors] path ...
+//
+// Each path is a file or a directory (scanned recursively).
+// The exit status is 1 if any query has an error.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/shellyln/go-open-soql-parser/soql/apex"
+)
+
+type jsonResult struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Col    int    `json:"col"`
+	Kind   string `json:"kind"`
+	Source string `json:"source"`
+	Error  string `json:"error,omitempty"`
+}
+
+func isApexFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".cls" || ext == ".trigger"
+}
+
+func collectFiles(paths []string) ([]string, error) {
+	files := make([]string, 0)
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, p)
+			continue
+		}
+		err = filepath.WalkDir(p, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && isApexFile(path) {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+func main() {
+	jsonOutput := flag.Bool("json", false, "print the results as JSON lines")
+	errorsOnly := flag.Bool("errors", false, "print the queries that have an error only")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: apexscan [-json] [-errors] path ...")
+		os.Exit(2)
+	}
+
+	files, err := collectFiles(flag.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	nQueries, nErrors := 0, 0
+
+	for _, file := range files {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+
+		for _, r := range apex.ScanAndParse(file, string(b)) {
+			nQueries++
+			if r.Err != nil {
+				nErrors++
+			} else if *errorsOnly {
+				continue
+			}
+
+			if *jsonOutput {
+				v := jsonResult{
+					File:   r.File,
+					Line:   r.Line,
+					Col:    r.Col,
+					Kind:   r.Kind.String(),
+					Source: r.Source,
+				}
+				if r.Err != nil {
+					v.Error = r.Err.Error()
+				}
+				if err := enc.Encode(v); err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					os.Exit(2)
+				}
+				continue
+			}
+
+			pos := r.File + ":" + strconv.Itoa(r.Line) + ":" + strconv.Itoa(r.Col)
+			if r.Err != nil {
+				fmt.Println(pos + ": error: " + firstLine(r.Err.Error()))
+			} else {
+				fmt.Println(pos + ": ok: " + r.Kind.String())
+			}
+		}
+	}
+
+	if !*jsonOutput {
+		fmt.Fprintf(os.Stderr, "%d queries, %d errors\n", nQueries, nErrors)
+	}
+	if nErrors != 0 {
+		os.Exit(1)
+	}
+}
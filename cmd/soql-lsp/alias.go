@@ -0,0 +1,126 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/shellyln/go-open-soql-parser/soql/cst"
+	. "github.com/shellyln/go-open-soql-parser/soql/parser/types"
+)
+
+// Query or subquery and the extent of its nodes in the source.
+type queryScope struct {
+	q      *SoqlQuery
+	parent *queryScope
+	start  int
+	end    int
+}
+
+func (s *queryScope) extend(span *SoqlSourceSpan) {
+	if span == nil {
+		return
+	}
+	if s.start < 0 || span.Offset < s.start {
+		s.start = span.Offset
+	}
+	if span.EndOffset > s.end {
+		s.end = span.EndOffset
+	}
+}
+
+func collectFieldScopes(z []*queryScope, s *queryScope, fields []SoqlFieldInfo) []*queryScope {
+	for i := 0; i < len(fields); i++ {
+		s.extend(fields[i].Span)
+		if fields[i].SubQuery != nil {
+			z = collectQueryScopes(z, fields[i].SubQuery, s)
+		}
+	}
+	return z
+}
+
+func collectConditionScopes(z []*queryScope, s *queryScope, cond []SoqlCondition) []*queryScope {
+	for i := 0; i < len(cond); i++ {
+		s.extend(cond[i].Span)
+		if cond[i].Opcode == SoqlConditionOpcode_FieldInfo && cond[i].Value.SubQuery != nil {
+			z = collectQueryScopes(z, cond[i].Value.SubQuery, s)
+		}
+	}
+	return z
+}
+
+func collectQueryScopes(z []*queryScope, q *SoqlQuery, parent *queryScope) []*queryScope {
+	s := &queryScope{q: q, parent: parent, start: -1}
+	z = append(z, s)
+
+	z = collectFieldScopes(z, s, q.Fields)
+	for i := 0; i < len(q.From); i++ {
+		s.extend(q.From[i].Span)
+	}
+	z = collectConditionScopes(z, s, q.Where)
+	z = collectFieldScopes(z, s, q.GroupBy)
+	z = collectConditionScopes(z, s, q.Having)
+	for i := 0; i < len(q.OrderBy); i++ {
+		s.extend(q.OrderBy[i].Span)
+	}
+	return z
+}
+
+// Alias at the cursor and the object that declares it.
+type aliasRef struct {
+	token  *cst.Token
+	object *SoqlObjectInfo
+	decl   *cst.Token // Alias token of the declaration; nil if it is not found.
+}
+
+// Returns the alias at the offset of the source, or nil if the token at the offset is not an alias.
+// The alias is looked up in the innermost query that includes the offset, and then in its parents.
+func findAlias(tree *cst.Tree, offset int) *aliasRef {
+	tok := tree.TokenAt(offset)
+	if tok == nil && offset > 0 {
+		// The cursor is at the end of the token.
+		tok = tree.TokenAt(offset - 1)
+	}
+	if tok == nil || tok.Kind != cst.TokenKind_Identifier {
+		return nil
+	}
+	for i, t := range tree.Tokens {
+		if t == tok && i > 0 && tree.Tokens[i-1].Text == "." {
+			// Not the head of the dotted name
+			return nil
+		}
+	}
+
+	scopes := make([]*queryScope, 0)
+	if tree.Query != nil {
+		scopes = collectQueryScopes(scopes, tree.Query, nil)
+	}
+	if tree.Search != nil {
+		for i := 0; i < len(tree.Search.Returning); i++ {
+			scopes = collectQueryScopes(scopes, &tree.Search.Returning[i], nil)
+		}
+	}
+
+	var innermost *queryScope
+	for _, s := range scopes {
+		if s.start <= tok.Offset && tok.End() <= s.end &&
+			(innermost == nil || s.end-s.start < innermost.end-innermost.start) {
+			innermost = s
+		}
+	}
+
+	for s := innermost; s != nil; s = s.parent {
+		for i := 0; i < len(s.q.From); i++ {
+			object := &s.q.From[i]
+			if object.AliasName == "" || !strings.EqualFold(object.AliasName, tok.Text) {
+				continue
+			}
+			z := &aliasRef{token: tok, object: object}
+			for _, t := range tree.TokensOf(object) {
+				if strings.EqualFold(t.Text, object.AliasName) {
+					z.decl = t
+				}
+			}
+			return z
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"sort"
+	"unicode/utf8"
+)
+
+// Text document opened by the client.
+type document struct {
+	uri        string
+	text       string
+	lineStarts []int
+}
+
+func newDocument(uri, text string) *document {
+	lineStarts := []int{0}
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '\r':
+			if i+1 < len(text) && text[i+1] == '\n' {
+				i++
+			}
+			lineStarts = append(lineStarts, i+1)
+		case '\n':
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+	return &document{uri: uri, text: text, lineStarts: lineStarts}
+}
+
+// Returns the number of the UTF-16 code units of the rune.
+func utf16RuneLen(r rune) int {
+	if r >= 0x10000 && r <= utf8.MaxRune {
+		return 2
+	}
+	return 1
+}
+
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16RuneLen(r)
+	}
+	return n
+}
+
+// Returns the LSP position of the byte offset.
+func (d *document) position(offset int) Position {
+	if offset > len(d.text) {
+		offset = len(d.text)
+	}
+	line := sort.Search(len(d.lineStarts), func(i int) bool {
+		return d.lineStarts[i] > offset
+	}) - 1
+	return Position{
+		Line:      line,
+		Character: utf16Len(d.text[d.lineStarts[line]:offset]),
+	}
+}
+
+func (d *document) rangeOf(start, end int) Range {
+	return Range{Start: d.position(start), End: d.position(end)}
+}
+
+// Returns the byte offset of the LSP position.
+func (d *document) offset(p Position) int {
+	if p.Line < 0 {
+		return 0
+	}
+	if p.Line >= len(d.lineStarts) {
+		return len(d.text)
+	}
+	end := len(d.text)
+	if p.Line+1 < len(d.lineStarts) {
+		end = d.lineStarts[p.Line+1]
+	}
+	i, n := d.lineStarts[p.Line], 0
+	for i < end && n < p.Character {
+		r, size := utf8.DecodeRuneInString(d.text[i:])
+		if r == '\r' || r == '\n' {
+			break
+		}
+		n += utf16RuneLen(r)
+		i += size
+	}
+	return i
+}
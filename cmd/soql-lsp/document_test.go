@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestDocumentPosition(t *testing.T) {
+	// "😀" is 4 bytes in UTF-8 and 2 code units in UTF-16; "あ" is 3 bytes and 1 code unit.
+	d := newDocument("file:///a.soql", "a😀b\r\nあ\nc")
+
+	tests := []struct {
+		name   string
+		offset int
+		want   Position
+	}{
+		{name: "start", offset: 0, want: Position{Line: 0, Character: 0}},
+		{name: "before surrogate pair", offset: 1, want: Position{Line: 0, Character: 1}},
+		{name: "after surrogate pair", offset: 5, want: Position{Line: 0, Character: 3}},
+		{name: "crlf", offset: 6, want: Position{Line: 0, Character: 4}},
+		{name: "second line", offset: 8, want: Position{Line: 1, Character: 0}},
+		{name: "after bmp character", offset: 11, want: Position{Line: 1, Character: 1}},
+		{name: "last line", offset: 12, want: Position{Line: 2, Character: 0}},
+		{name: "end", offset: 13, want: Position{Line: 2, Character: 1}},
+		{name: "beyond end", offset: 100, want: Position{Line: 2, Character: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := d.position(tt.offset); got != tt.want {
+				t.Errorf("position() = %v, want %v", got, tt.want)
+			}
+			if tt.offset <= len(d.text) {
+				if got := d.offset(tt.want); got != tt.offset {
+					t.Errorf("offset() = %v, want %v", got, tt.offset)
+				}
+			}
+		})
+	}
+}
+
+func TestDocumentOffset(t *testing.T) {
+	d := newDocument("file:///a.soql", "a😀b\r\nあ\nc")
+
+	tests := []struct {
+		name string
+		pos  Position
+		want int
+	}{
+		{name: "middle of surrogate pair", pos: Position{Line: 0, Character: 2}, want: 5},
+		{name: "beyond end of line", pos: Position{Line: 0, Character: 10}, want: 6},
+		{name: "beyond end of lf line", pos: Position{Line: 1, Character: 5}, want: 11},
+		{name: "negative line", pos: Position{Line: -1, Character: 0}, want: 0},
+		{name: "beyond last line", pos: Position{Line: 5, Character: 0}, want: 13},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := d.offset(tt.pos); got != tt.want {
+				t.Errorf("offset() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadMessage(t *testing.T) {
+	type args struct {
+		src string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []string
+		wantErr bool
+	}{{
+		name: "single",
+		args: args{src: "Content-Length: 17\r\n\r\n{\"method\":\"exit\"}"},
+		want: []string{`{"method":"exit"}`},
+	}, {
+		name: "multiple with content type",
+		args: args{src: "Content-Length: 2\r\nContent-Type: application/vscode-jsonrpc; charset=utf-8\r\n\r\n{}" +
+			"content-length: 5\r\n\r\n\"あ\""},
+		want: []string{`{}`, `"あ"`},
+	}, {
+		name:    "no content length",
+		args:    args{src: "Content-Type: application/json\r\n\r\n{}"},
+		want:    []string{},
+		wantErr: true,
+	}, {
+		name:    "short body",
+		args:    args{src: "Content-Length: 10\r\n\r\n{}"},
+		want:    []string{},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := bufio.NewReader(strings.NewReader(tt.args.src))
+			got := make([]string, 0)
+			for {
+				b, err := readMessage(r)
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					if !tt.wantErr {
+						t.Errorf("readMessage() error = %v", err)
+					}
+					return
+				}
+				got = append(got, string(b))
+			}
+			if tt.wantErr {
+				t.Errorf("readMessage() = %v, want error", got)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readMessage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteMessage(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeMessage(&buf, response{JSONRPC: "2.0", ID: []byte("1"), Result: "é"}); err != nil {
+		t.Errorf("writeMessage() error = %v", err)
+		return
+	}
+	body := `{"jsonrpc":"2.0","id":1,"result":"é"}`
+	if want := "Content-Length: 38\r\n\r\n" + body; buf.String() != want {
+		t.Errorf("writeMessage() = %q, want %q", buf.String(), want)
+	}
+
+	b, err := readMessage(bufio.NewReader(&buf))
+	if err != nil || string(b) != body {
+		t.Errorf("readMessage() = %q, %v, want %q", b, err, body)
+	}
+}
@@ -0,0 +1,77 @@
+// Command soql-lsp is the language server of the SOQL and SOSL files (*.soql).
+// It speaks the Language Server Protocol over the standard input and output.
+//
+// Usage:
+//
+//	soql-lsp
+//
+// Features:
+//   - Diagnostics (syntax and normalization errors)
+//   - Hover on the object aliases (fully qualified name of the object)
+//   - Go to definition from the alias to its declaration in the from clause
+//   - Document formatting
+//   - Semantic tokens
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+func main() {
+	r := bufio.NewReader(os.Stdin)
+	w := bufio.NewWriter(os.Stdout)
+	s := newServer(w)
+
+	for {
+		b, err := readMessage(r)
+		if err != nil {
+			if err != io.EOF {
+				fmt.Fprintln(os.Stderr, err)
+			}
+			os.Exit(1)
+		}
+
+		var req request
+		if err := json.Unmarshal(b, &req); err != nil {
+			writeMessage(w, errorResponse{
+				JSONRPC: "2.0",
+				ID:      json.RawMessage("null"),
+				Error:   &responseError{Code: codeParseError, Message: err.Error()},
+			})
+			w.Flush()
+			continue
+		}
+
+		if req.Method == "exit" {
+			w.Flush()
+			if s.shutdown {
+				os.Exit(0)
+			}
+			os.Exit(1)
+		}
+
+		if req.ID == nil {
+			// Notification; Unknown notifications (e.g. $/cancelRequest) are ignored.
+			s.handle(&req)
+		} else if req.Method == "" {
+			writeMessage(w, errorResponse{
+				JSONRPC: "2.0",
+				ID:      req.ID,
+				Error:   &responseError{Code: codeInvalidRequest, Message: "Method is required"},
+			})
+		} else if result, rErr := s.handle(&req); rErr != nil {
+			writeMessage(w, errorResponse{JSONRPC: "2.0", ID: req.ID, Error: rErr})
+		} else {
+			writeMessage(w, response{JSONRPC: "2.0", ID: req.ID, Result: result})
+		}
+
+		if err := w.Flush(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/shellyln/go-open-soql-parser/soql/cst"
+	"github.com/shellyln/go-open-soql-parser/soql/parser"
+	"github.com/shellyln/go-open-soql-parser/soql/parser/core/class"
+	"github.com/shellyln/go-open-soql-parser/soql/parser/types"
+)
+
+var semanticTokenTypes = []string{
+	"keyword", "property", "variable", "function", "string", "number",
+	"comment", "operator", "parameter", "enumMember", "decorator",
+}
+
+// Token class -> index of semanticTokenTypes
+var semanticTokenTypeOf = map[string]int{
+	class.Keyword:             0,
+	class.Bool:                0,
+	class.Null:                0,
+	class.Identifier:          1,
+	class.Alias:               2,
+	class.FunctionName:        3,
+	class.String:              4,
+	class.Int:                 5,
+	class.Float:               5,
+	class.Currency:            5,
+	class.Date:                5,
+	class.DateTime:            5,
+	class.Time:                5,
+	class.Comment:             6,
+	class.Operator:            7,
+	class.ParameterizedValue:  8,
+	class.DateTimeLiteralName: 9,
+	class.Hints:               10,
+}
+
+type server struct {
+	out      io.Writer
+	docs     map[string]*document
+	shutdown bool
+}
+
+func newServer(out io.Writer) *server {
+	return &server{
+		out:  out,
+		docs: make(map[string]*document),
+	}
+}
+
+func (s *server) notify(method string, params interface{}) error {
+	return writeMessage(s.out, notification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+// Handle the request or the notification.
+// If the message is a request, the result or the error is returned.
+func (s *server) handle(req *request) (interface{}, *responseError) {
+	switch req.Method {
+	case "initialize":
+		return InitializeResult{
+			Capabilities: ServerCapabilities{
+				TextDocumentSync:           1,
+				HoverProvider:              true,
+				DefinitionProvider:         true,
+				DocumentFormattingProvider: true,
+				SemanticTokensProvider: SemanticTokensOptions{
+					Legend: SemanticTokensLegend{
+						TokenTypes:     semanticTokenTypes,
+						TokenModifiers: []string{},
+					},
+					Full: true,
+				},
+			},
+			ServerInfo: ServerInfo{Name: "soql-lsp"},
+		}, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		s.open(params.TextDocument.URI, params.TextDocument.Text)
+		return nil, nil
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		if n := len(params.ContentChanges); n != 0 {
+			// Full text document sync
+			s.open(params.TextDocument.URI, params.ContentChanges[n-1].Text)
+		}
+		return nil, nil
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		delete(s.docs, params.TextDocument.URI)
+		s.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{
+			URI:         params.TextDocument.URI,
+			Diagnostics: []Diagnostic{},
+		})
+		return nil, nil
+	case "textDocument/hover":
+		var params TextDocumentPositionParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		return s.hover(params), nil
+	case "textDocument/definition":
+		var params TextDocumentPositionParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		return s.definition(params), nil
+	case "textDocument/formatting":
+		var params DocumentFormattingParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		return s.formatting(params), nil
+	case "textDocument/semanticTokens/full":
+		var params SemanticTokensParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &responseError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		return s.semanticTokens(params), nil
+	}
+	return nil, &responseError{Code: codeMethodNotFound, Message: "Method not found: " + req.Method}
+}
+
+func (s *server) open(uri, text string) {
+	d := newDocument(uri, text)
+	s.docs[uri] = d
+	s.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{
+		URI:         uri,
+		Diagnostics: d.diagnostics(),
+	})
+}
+
+func isSearch(text string) bool {
+	tokens, _ := cst.Lex(text)
+	return len(tokens) != 0 && strings.EqualFold(tokens[0].Text, "find")
+}
+
+// Parse the document in the error recovery mode and returns the errors as the diagnostics.
+func (d *document) diagnostics() []Diagnostic {
+	z := make([]Diagnostic, 0)
+	if strings.TrimSpace(d.text) == "" {
+		return z
+	}
+
+	var err error
+	options := parser.ParseOptions{Recovery: true}
+	if isSearch(d.text) {
+		_, err = parser.ParseSoslWithOptions(d.text, options)
+	} else {
+		_, err = parser.ParseWithOptions(d.text, options)
+	}
+	if err == nil {
+		return z
+	}
+
+	var list types.ParseErrorList
+	var parseErr *types.ParseError
+	switch {
+	case errors.As(err, &list):
+	case errors.As(err, &parseErr):
+		list = types.ParseErrorList{parseErr}
+	default:
+		return append(z, Diagnostic{
+			Range:    d.rangeOf(0, len(d.text)),
+			Severity: 1,
+			Source:   "soql",
+			Message:  err.Error(),
+		})
+	}
+
+	for _, e := range list {
+		z = append(z, Diagnostic{
+			Range:    d.rangeOf(e.Offset, e.EndOffset),
+			Severity: 1,
+			Code:     e.Code.String(),
+			Source:   "soql",
+			Message:  e.Message,
+		})
+	}
+	return z
+}
+
+func (s *server) alias(params TextDocumentPositionParams) (*document, *aliasRef) {
+	d, ok := s.docs[params.TextDocument.URI]
+	if !ok {
+		return nil, nil
+	}
+	tree, err := cst.Parse(d.text)
+	if err != nil {
+		return nil, nil
+	}
+	return d, findAlias(tree, d.offset(params.Position))
+}
+
+// Show the fully qualified name of the object that is referred by the alias.
+func (s *server) hover(params TextDocumentPositionParams) *Hover {
+	d, ref := s.alias(params)
+	if ref == nil {
+		return nil
+	}
+	return &Hover{
+		Contents: MarkupContent{
+			Kind:  "markdown",
+			Value: "```soql\n" + ref.object.AliasName + ": " + strings.Join(ref.object.Name, ".") + "\n```",
+		},
+		Range: d.rangeOf(ref.token.Offset, ref.token.End()),
+	}
+}
+
+// Go to the declaration of the alias in the from clause.
+func (s *server) definition(params TextDocumentPositionParams) *Location {
+	d, ref := s.alias(params)
+	if ref == nil || ref.decl == nil {
+		return nil
+	}
+	return &Location{
+		URI:   d.uri,
+		Range: d.rangeOf(ref.decl.Offset, ref.decl.End()),
+	}
+}
+
+// Format the whole document; No edits are returned if the document has errors.
+func (s *server) formatting(params DocumentFormattingParams) []TextEdit {
+	z := make([]TextEdit, 0)
+	d, ok := s.docs[params.TextDocument.URI]
+	if !ok {
+		return z
+	}
+	formatted, err := cst.Format(d.text)
+	if err != nil || formatted == d.text {
+		return z
+	}
+	return append(z, TextEdit{
+		Range:   d.rangeOf(0, len(d.text)),
+		NewText: formatted,
+	})
+}
+
+// Encode the classified tokens as the semantic tokens.
+// Tokens that span multiple lines (e.g. block comments) are split into the lines.
+func (s *server) semanticTokens(params SemanticTokensParams) *SemanticTokens {
+	z := &SemanticTokens{Data: make([]int, 0)}
+	d, ok := s.docs[params.TextDocument.URI]
+	if !ok {
+		return z
+	}
+
+	prev := Position{}
+	for _, tok := range cst.Tokenize(d.text) {
+		tokenType, ok := semanticTokenTypeOf[tok.Class]
+		if !ok {
+			continue
+		}
+		for start := tok.Offset; start < tok.EndOffset; {
+			end := tok.EndOffset
+			if i := strings.IndexAny(d.text[start:end], "\r\n"); i >= 0 {
+				end = start + i
+			}
+			if start < end {
+				pos := d.position(start)
+				deltaChar := pos.Character
+				if pos.Line == prev.Line {
+					deltaChar -= prev.Character
+				}
+				z.Data = append(z.Data, pos.Line-prev.Line, deltaChar, utf16Len(d.text[start:end]), tokenType, 0)
+				prev = pos
+			}
+			// Skip the line break.
+			for end < tok.EndOffset && (d.text[end] == '\r' || d.text[end] == '\n') {
+				end++
+			}
+			start = end
+		}
+	}
+	return z
+}
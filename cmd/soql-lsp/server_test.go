@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func openDocument(uri, text string) (*server, []PublishDiagnosticsParams) {
+	var buf bytes.Buffer
+	s := newServer(&buf)
+	params, _ := json.Marshal(DidOpenTextDocumentParams{
+		TextDocument: TextDocumentItem{URI: uri, LanguageID: "soql", Version: 1, Text: text},
+	})
+	s.handle(&request{JSONRPC: "2.0", Method: "textDocument/didOpen", Params: params})
+
+	published := make([]PublishDiagnosticsParams, 0)
+	r := bufio.NewReader(&buf)
+	for {
+		b, err := readMessage(r)
+		if err != nil {
+			break
+		}
+		var n struct {
+			Method string                   `json:"method"`
+			Params PublishDiagnosticsParams `json:"params"`
+		}
+		if json.Unmarshal(b, &n) == nil && n.Method == "textDocument/publishDiagnostics" {
+			published = append(published, n.Params)
+		}
+	}
+	return s, published
+}
+
+func TestDiagnostics(t *testing.T) {
+	type args struct {
+		text string
+	}
+	tests := []struct {
+		name string
+		args args
+		want []Diagnostic
+	}{{
+		name: "no errors",
+		args: args{text: "SELECT Id FROM Account"},
+		want: []Diagnostic{},
+	}, {
+		name: "empty",
+		args: args{text: "  \n"},
+		want: []Diagnostic{},
+	}, {
+		name: "field list",
+		args: args{text: "SELECT Id, FROM Account"},
+		want: []Diagnostic{{
+			Range:    Range{Start: Position{Line: 0, Character: 10}, End: Position{Line: 0, Character: 11}},
+			Severity: 1,
+			Code:     "Syntax",
+			Source:   "soql",
+			Message:  "Unexpected token aheads near by the select clause (field list)",
+		}},
+	}, {
+		name: "after surrogate pair",
+		args: args{text: "SELECT Id FROM Account\nWHERE Name = '😀' x"},
+		want: []Diagnostic{{
+			Range:    Range{Start: Position{Line: 1, Character: 18}, End: Position{Line: 1, Character: 19}},
+			Severity: 1,
+			Code:     "Syntax",
+			Source:   "soql",
+			Message:  "Unexpected token aheads",
+		}},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, got := openDocument("file:///a.soql", tt.args.text)
+			want := []PublishDiagnosticsParams{{URI: "file:///a.soql", Diagnostics: tt.want}}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("publishDiagnostics = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestHoverAndDefinition(t *testing.T) {
+	const uri = "file:///a.soql"
+	s, _ := openDocument(uri, "SELECT c.Name, (SELECT o.Id FROM c.Opportunities o)\nFROM Contact c")
+
+	tests := []struct {
+		name      string
+		pos       Position
+		wantHover *Hover
+		wantDef   *Location
+	}{{
+		name: "alias",
+		pos:  Position{Line: 0, Character: 7},
+		wantHover: &Hover{
+			Contents: MarkupContent{Kind: "markdown", Value: "```soql\nc: Contact\n```"},
+			Range:    Range{Start: Position{Line: 0, Character: 7}, End: Position{Line: 0, Character: 8}},
+		},
+		wantDef: &Location{
+			URI:   uri,
+			Range: Range{Start: Position{Line: 1, Character: 13}, End: Position{Line: 1, Character: 14}},
+		},
+	}, {
+		name: "alias of subquery",
+		pos:  Position{Line: 0, Character: 23},
+		wantHover: &Hover{
+			Contents: MarkupContent{Kind: "markdown", Value: "```soql\no: Contact.Opportunities\n```"},
+			Range:    Range{Start: Position{Line: 0, Character: 23}, End: Position{Line: 0, Character: 24}},
+		},
+		wantDef: &Location{
+			URI:   uri,
+			Range: Range{Start: Position{Line: 0, Character: 49}, End: Position{Line: 0, Character: 50}},
+		},
+	}, {
+		name: "parent alias in subquery",
+		pos:  Position{Line: 0, Character: 33},
+		wantHover: &Hover{
+			Contents: MarkupContent{Kind: "markdown", Value: "```soql\nc: Contact\n```"},
+			Range:    Range{Start: Position{Line: 0, Character: 33}, End: Position{Line: 0, Character: 34}},
+		},
+		wantDef: &Location{
+			URI:   uri,
+			Range: Range{Start: Position{Line: 1, Character: 13}, End: Position{Line: 1, Character: 14}},
+		},
+	}, {
+		name: "field",
+		pos:  Position{Line: 0, Character: 10},
+	}, {
+		name: "keyword",
+		pos:  Position{Line: 1, Character: 1},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := TextDocumentPositionParams{TextDocument: TextDocumentIdentifier{URI: uri}, Position: tt.pos}
+			if got := s.hover(params); !reflect.DeepEqual(got, tt.wantHover) {
+				t.Errorf("hover() = %+v, want %+v", got, tt.wantHover)
+			}
+			if got := s.definition(params); !reflect.DeepEqual(got, tt.wantDef) {
+				t.Errorf("definition() = %+v, want %+v", got, tt.wantDef)
+			}
+		})
+	}
+}
+
+func TestSemanticTokens(t *testing.T) {
+	const uri = "file:///a.soql"
+	s, _ := openDocument(uri, "SELECT c.Name\n/* 😀\n */ FROM Contact c")
+
+	got := s.semanticTokens(SemanticTokensParams{TextDocument: TextDocumentIdentifier{URI: uri}})
+	// deltaLine, deltaStartChar, length, tokenType, tokenModifiers
+	want := []int{
+		0, 0, 6, 0, 0, // SELECT
+		0, 7, 1, 2, 0, // c
+		0, 2, 4, 1, 0, // Name
+		1, 0, 5, 6, 0, // "/* 😀" (the surrogate pair is 2 code units)
+		1, 0, 3, 6, 0, // " */"
+		0, 4, 4, 0, 0, // FROM
+		0, 5, 7, 1, 0, // Contact
+		0, 8, 1, 2, 0, // c
+	}
+	if !reflect.DeepEqual(got.Data, want) {
+		t.Errorf("semanticTokens() = %v, want %v", got.Data, want)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/shellyln/go-open-soql-parser/soql/parser"
+	"github.com/shellyln/go-open-soql-parser/soql/parser/types"
+)
+
+// Returns the errors of the query; All syntax errors are reported by the error recovery mode.
+func checkQuery(text string) []error {
+	_, _, err := parseQuery(text, parser.ParseOptions{Recovery: true})
+	if err == nil {
+		return nil
+	}
+
+	var list types.ParseErrorList
+	var parseErr *types.ParseError
+	switch {
+	case errors.As(err, &list):
+	case errors.As(err, &parseErr):
+		list = types.ParseErrorList{parseErr}
+	default:
+		return []error{err}
+	}
+
+	z := make([]error, len(list))
+	for i := 0; i < len(list); i++ {
+		z[i] = list[i]
+	}
+	return z
+}
+
+// Checks the files and prints the errors as "file:line:col: error: message".
+func runCheck(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	files, err := collectFiles(flags.Args())
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	nErrors := 0
+	for _, path := range files {
+		text, err := readFile(path)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+
+		for _, err := range checkQuery(text) {
+			nErrors++
+			var parseErr *types.ParseError
+			if errors.As(err, &parseErr) {
+				pos := displayName(path) + ":" + strconv.Itoa(parseErr.Line) + ":" + strconv.Itoa(parseErr.Col)
+				fmt.Fprintln(stdout, pos+": error: "+firstLine(parseErr.Message)+" ("+parseErr.Code.String()+")")
+			} else {
+				fmt.Fprintln(stdout, displayName(path)+": error: "+firstLine(err.Error()))
+			}
+		}
+	}
+
+	fmt.Fprintf(stderr, "%d files, %d errors\n", len(files), nErrors)
+	if nErrors != 0 {
+		return 1
+	}
+	return 0
+}
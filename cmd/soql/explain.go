@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/shellyln/go-open-soql-parser/soql/parser"
+	. "github.com/shellyln/go-open-soql-parser/soql/parser/types"
+)
+
+// Node of the object graph (view graph)
+type viewNode struct {
+	id       int
+	leaf     *SoqlViewGraphLeaf
+	children []*viewNode
+}
+
+// Returns the roots of the object graph; The objects of the subqueries on the conditions are also roots.
+func buildViewTree(meta *SoqlQueryMeta) []*viewNode {
+	nodes := make(map[int]*viewNode, len(meta.ViewGraph))
+	ids := make([]int, 0, len(meta.ViewGraph))
+	for id := range meta.ViewGraph {
+		leaf := meta.ViewGraph[id]
+		nodes[id] = &viewNode{id: id, leaf: &leaf}
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	roots := make([]*viewNode, 0)
+	for _, id := range ids {
+		node := nodes[id]
+		if parent, ok := nodes[node.leaf.ParentViewId]; ok {
+			parent.children = append(parent.children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+	return roots
+}
+
+func viewLabel(node *viewNode, meta *SoqlQueryMeta) string {
+	leaf := node.leaf
+	var sb strings.Builder
+
+	sb.WriteString(leaf.Name)
+	if leaf.Object != nil && leaf.Object.AliasName != "" {
+		sb.WriteString(" " + leaf.Object.AliasName)
+	}
+
+	attrs := []string{"view " + strconv.Itoa(node.id), "query " + strconv.Itoa(leaf.QueryId)}
+	if q, ok := meta.QueryGraph[leaf.QueryId]; ok && q.IsConditional && leaf.ParentViewId == 0 {
+		attrs = append(attrs, "condition of query "+strconv.Itoa(q.ParentQueryId))
+	}
+	if leaf.PolymorphicType != "" {
+		attrs = append(attrs, "typeof "+leaf.PolymorphicType)
+	}
+	if leaf.Many {
+		attrs = append(attrs, "many")
+	}
+	if leaf.InnerJoin {
+		attrs = append(attrs, "inner join")
+	}
+	if leaf.NonResult {
+		attrs = append(attrs, "non-result")
+	}
+
+	sb.WriteString("  (" + strings.Join(attrs, ", ") + ")")
+	return sb.String()
+}
+
+// Writes the node and its descendants with the box drawing characters.
+// The per-object query is written under the label of each object.
+func writeViewTree(w io.Writer, node *viewNode, meta *SoqlQueryMeta, prefix, branch, indent string) {
+	fmt.Fprintln(w, prefix+branch+viewLabel(node, meta))
+
+	childPrefix := prefix + indent
+	if node.leaf.Object != nil && node.leaf.Object.PerObjectQuery != nil {
+		if s, err := parser.Format(node.leaf.Object.PerObjectQuery); err == nil {
+			bar := "    "
+			if len(node.children) != 0 {
+				bar = "│   "
+			}
+			fmt.Fprintln(w, childPrefix+bar+s)
+		}
+	}
+
+	for i, child := range node.children {
+		if i == len(node.children)-1 {
+			writeViewTree(w, child, meta, childPrefix, "└── ", "    ")
+		} else {
+			writeViewTree(w, child, meta, childPrefix, "├── ", "│   ")
+		}
+	}
+}
+
+func writeExplain(w io.Writer, meta *SoqlQueryMeta) {
+	if meta == nil {
+		return
+	}
+	for _, root := range buildViewTree(meta) {
+		writeViewTree(w, root, meta, "", "", "")
+	}
+}
+
+// Prints the object graph and the per-object queries as a tree.
+// Each object of the SOSL returning clause is printed as an independent query.
+func runExplain(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("explain", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	path, ok := singleFile(flags)
+	if !ok {
+		fmt.Fprintln(stderr, "usage: soql explain [file]")
+		return 2
+	}
+
+	text, err := readFile(path)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	q, sq, err := parseQuery(text, parser.ParseOptions{})
+	if err != nil {
+		fmt.Fprintln(stderr, displayName(path)+": "+err.Error())
+		return 1
+	}
+
+	w := bufio.NewWriter(stdout)
+	if sq != nil {
+		for i := 0; i < len(sq.Returning); i++ {
+			if i != 0 {
+				fmt.Fprintln(w)
+			}
+			writeExplain(w, sq.Returning[i].Meta)
+		}
+	} else {
+		writeExplain(w, q.Meta)
+	}
+
+	if err := w.Flush(); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	return 0
+}
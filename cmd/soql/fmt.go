@@ -0,0 +1,70 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/shellyln/go-open-soql-parser/soql/cst"
+)
+
+// Formats the files in place; The standard input is formatted to the standard output.
+// With -l, the files whose formatting differs are listed and not rewritten.
+func runFmt(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	list := flags.Bool("l", false, "list the files whose formatting differs and do not rewrite them")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	files, err := collectFiles(flags.Args())
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	status := 0
+	for _, path := range files {
+		text, err := readFile(path)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+
+		formatted, err := cst.Format(text)
+		if err != nil {
+			fmt.Fprintln(stderr, displayName(path)+": "+firstLine(err.Error()))
+			status = 1
+			continue
+		}
+
+		if *list {
+			if formatted != text {
+				fmt.Fprintln(stdout, displayName(path))
+				status = 1
+			}
+			continue
+		}
+
+		if path == stdinPath {
+			fmt.Fprint(stdout, formatted)
+			continue
+		}
+		if formatted == text {
+			continue
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+		if err := os.WriteFile(path, []byte(formatted), info.Mode().Perm()); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+	}
+	return status
+}
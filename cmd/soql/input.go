@@ -0,0 +1,110 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/shellyln/go-open-soql-parser/soql/cst"
+	"github.com/shellyln/go-open-soql-parser/soql/parser"
+	"github.com/shellyln/go-open-soql-parser/soql/parser/types"
+)
+
+// Path of the standard input
+const stdinPath = "-"
+
+func isSoqlFile(path string) bool {
+	return strings.ToLower(filepath.Ext(path)) == ".soql"
+}
+
+// Returns the files of the paths; Directories are walked recursively and their *.soql files are collected.
+// If no path is given, the standard input is returned.
+func collectFiles(paths []string) ([]string, error) {
+	if len(paths) == 0 {
+		return []string{stdinPath}, nil
+	}
+
+	files := make([]string, 0)
+	for _, p := range paths {
+		if p == stdinPath {
+			files = append(files, p)
+			continue
+		}
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, p)
+			continue
+		}
+		err = filepath.WalkDir(p, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && isSoqlFile(path) {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+func readFile(path string) (string, error) {
+	var b []byte
+	var err error
+	if path == stdinPath {
+		b, err = io.ReadAll(os.Stdin)
+	} else {
+		b, err = os.ReadFile(path)
+	}
+	return string(b), err
+}
+
+// Returns the name of the file in the messages.
+func displayName(path string) string {
+	if path == stdinPath {
+		return "<stdin>"
+	}
+	return path
+}
+
+// Returns the file of the single file commands (parse and explain).
+func singleFile(flags *flag.FlagSet) (string, bool) {
+	switch flags.NArg() {
+	case 0:
+		return stdinPath, true
+	case 1:
+		return flags.Arg(0), true
+	}
+	return "", false
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+func isSearch(text string) bool {
+	tokens, _ := cst.Lex(text)
+	return len(tokens) != 0 && strings.EqualFold(tokens[0].Text, "find")
+}
+
+// Parses the SOQL query, or the SOSL query if the text starts with FIND.
+func parseQuery(text string, options parser.ParseOptions) (*types.SoqlQuery, *types.SoslQuery, error) {
+	if isSearch(text) {
+		q, err := parser.ParseSoslWithOptions(text, options)
+		return nil, q, err
+	}
+	q, err := parser.ParseWithOptions(text, options)
+	return q, nil, err
+}
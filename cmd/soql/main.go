@@ -0,0 +1,65 @@
+// Command soql parses, formats, checks and explains the SOQL and SOSL queries.
+//
+// Usage:
+//
+//	soql parse [-compact] [file]
+//	soql fmt [-l] [path ...]
+//	soql check [path ...]
+//	soql explain [file]
+//	soql version
+//
+// If no file is given or the file is "-", the query is read from the standard input.
+// Each path of fmt and check is a file or a directory (*.soql files are processed recursively).
+//
+// The exit status is 1 if any query has an error (or any file is not formatted with fmt -l),
+// and 2 if the command line is invalid or a file cannot be read.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const usage = `usage: soql <command> [arguments]
+
+Commands:
+  parse    print the normalized query as JSON
+  fmt      format the files in place
+  check    check the files and print the errors
+  explain  print the object graph and the per-object queries
+  version  print the version`
+
+func versionString() string {
+	if Version == "" {
+		// Not built by the Makefile
+		return "(devel)"
+	}
+	return Version + " (" + Revision + ")"
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	args := os.Args[2:]
+	switch os.Args[1] {
+	case "parse":
+		os.Exit(runParse(args, os.Stdout, os.Stderr))
+	case "fmt":
+		os.Exit(runFmt(args, os.Stdout, os.Stderr))
+	case "check":
+		os.Exit(runCheck(args, os.Stdout, os.Stderr))
+	case "explain":
+		os.Exit(runExplain(args, os.Stdout, os.Stderr))
+	case "version":
+		fmt.Println("soql " + versionString())
+	case "help", "-h", "-help", "--help":
+		fmt.Println(usage)
+	default:
+		fmt.Fprintln(os.Stderr, "soql: unknown command "+os.Args[1])
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
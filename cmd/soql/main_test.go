@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/shellyln/go-open-soql-parser/soql/parser/types"
+)
+
+// Writes the files to a temporary directory and returns the directory.
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, text := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// Returns the paths in the directory.
+func joinPaths(dir string, paths []string) []string {
+	z := make([]string, len(paths))
+	for i, p := range paths {
+		z[i] = filepath.Join(dir, p)
+	}
+	return z
+}
+
+// Removes the directory from the paths in the output.
+func trimDir(dir, s string) string {
+	return filepath.ToSlash(strings.ReplaceAll(s, dir+string(filepath.Separator), ""))
+}
+
+func TestCheck(t *testing.T) {
+	type args struct {
+		files map[string]string
+		paths []string
+	}
+	tests := []struct {
+		name       string
+		args       args
+		want       int
+		wantStdout string
+		wantStderr string
+	}{{
+		name:       "valid",
+		args:       args{files: map[string]string{"a.soql": "SELECT Id FROM Account\n"}, paths: []string{"a.soql"}},
+		want:       0,
+		wantStdout: "",
+		wantStderr: "1 files, 0 errors\n",
+	}, {
+		name:       "invalid",
+		args:       args{files: map[string]string{"a.soql": "SELECT Id\nFROM Account\nWHERE Name = ORDER BY Id\n"}, paths: []string{"a.soql"}},
+		want:       1,
+		wantStdout: "a.soql:3:14: error: Unexpected token aheads near by the 'where' clause (unknown operand2) (Syntax)\n",
+		wantStderr: "1 files, 1 errors\n",
+	}, {
+		name: "directory",
+		args: args{files: map[string]string{
+			"q/a.soql":   "SELECT Id FROM Account",
+			"q/b/c.soql": "SELECT Id, FROM Account",
+			"q/d.txt":    "not a query",
+		}, paths: []string{"q"}},
+		want:       1,
+		wantStdout: "q/b/c.soql:1:11: error: Unexpected token aheads near by the select clause (field list) (Syntax)\n",
+		wantStderr: "2 files, 1 errors\n",
+	}, {
+		name:       "unreadable",
+		args:       args{files: map[string]string{}, paths: []string{"missing.soql"}},
+		want:       2,
+		wantStdout: "",
+		wantStderr: "stat missing.soql: no such file or directory\n",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFiles(t, tt.args.files)
+
+			var stdout, stderr bytes.Buffer
+			if got := runCheck(joinPaths(dir, tt.args.paths), &stdout, &stderr); got != tt.want {
+				t.Errorf("runCheck() = %v, want %v", got, tt.want)
+			}
+			if got := trimDir(dir, stdout.String()); got != tt.wantStdout {
+				t.Errorf("runCheck() stdout = %q, want %q", got, tt.wantStdout)
+			}
+			if got := trimDir(dir, stderr.String()); got != tt.wantStderr {
+				t.Errorf("runCheck() stderr = %q, want %q", got, tt.wantStderr)
+			}
+		})
+	}
+}
+
+func TestFmt(t *testing.T) {
+	type args struct {
+		files map[string]string
+		flags []string
+	}
+	tests := []struct {
+		name       string
+		args       args
+		want       int
+		wantStdout string
+		wantFiles  map[string]string
+	}{{
+		name: "list",
+		args: args{files: map[string]string{
+			"a.soql": "select id from account",
+			"b.soql": "SELECT Id\nFROM Account\n",
+		}, flags: []string{"-l"}},
+		want:       1,
+		wantStdout: "a.soql\n",
+		wantFiles: map[string]string{
+			"a.soql": "select id from account",
+			"b.soql": "SELECT Id\nFROM Account\n",
+		},
+	}, {
+		name:       "list formatted",
+		args:       args{files: map[string]string{"b.soql": "SELECT Id\nFROM Account\n"}, flags: []string{"-l"}},
+		want:       0,
+		wantStdout: "",
+		wantFiles:  map[string]string{"b.soql": "SELECT Id\nFROM Account\n"},
+	}, {
+		name: "in place",
+		args: args{files: map[string]string{
+			"a.soql": "select id from account",
+			"b.soql": "SELECT Id\nFROM Account\n",
+		}},
+		want:       0,
+		wantStdout: "",
+		wantFiles: map[string]string{
+			"a.soql": "SELECT id\nFROM account",
+			"b.soql": "SELECT Id\nFROM Account\n",
+		},
+	}, {
+		name:       "syntax error",
+		args:       args{files: map[string]string{"a.soql": "select id, from account"}},
+		want:       1,
+		wantStdout: "",
+		wantFiles:  map[string]string{"a.soql": "select id, from account"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFiles(t, tt.args.files)
+
+			var stdout, stderr bytes.Buffer
+			if got := runFmt(append(tt.args.flags, dir), &stdout, &stderr); got != tt.want {
+				t.Errorf("runFmt() = %v, want %v; %v", got, tt.want, stderr.String())
+			}
+			if got := trimDir(dir, stdout.String()); got != tt.wantStdout {
+				t.Errorf("runFmt() stdout = %q, want %q", got, tt.wantStdout)
+			}
+			for name, want := range tt.wantFiles {
+				b, err := os.ReadFile(filepath.Join(dir, name))
+				if err != nil || string(b) != want {
+					t.Errorf("runFmt() %v = %q, %v, want %q", name, b, err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestExplain(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.soql": "SELECT Id, (SELECT Id FROM Contacts) FROM Account WHERE Id IN (SELECT AccountId FROM Opportunity)",
+	})
+
+	var stdout, stderr bytes.Buffer
+	if got := runExplain([]string{filepath.Join(dir, "a.soql")}, &stdout, &stderr); got != 0 {
+		t.Errorf("runExplain() = %v, want 0; %v", got, stderr.String())
+	}
+	want := "" +
+		"Account  (view 1, query 1)\n" +
+		"│   SELECT Id FROM Account\n" +
+		"└── Contacts  (view 2, query 2, many)\n" +
+		"        SELECT Id FROM Account.Contacts\n" +
+		"Opportunity  (view 3, query 3, condition of query 1, non-result)\n" +
+		"    SELECT AccountId FROM Opportunity\n"
+	if stdout.String() != want {
+		t.Errorf("runExplain() = %v, want %v", stdout.String(), want)
+	}
+}
+
+func TestParse(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.soql": "SELECT Id FROM Account a WHERE a.Name = 'x'",
+		"b.soql": "FIND {x} RETURNING Contact(Name)",
+		"c.soql": "SELECT FROM Account",
+	})
+
+	var stdout, stderr bytes.Buffer
+	if got := runParse([]string{"-compact", filepath.Join(dir, "a.soql")}, &stdout, &stderr); got != 0 {
+		t.Errorf("runParse() = %v, want 0; %v", got, stderr.String())
+	}
+	var q types.SoqlQuery
+	if err := json.Unmarshal(stdout.Bytes(), &q); err != nil {
+		t.Errorf("runParse() = %v, %v", stdout.String(), err)
+		return
+	}
+	if len(q.Fields) != 2 || !reflect.DeepEqual(q.Fields[0].Name, []string{"Account", "Id"}) ||
+		len(q.From) != 1 || q.From[0].AliasName != "a" ||
+		len(q.Where) != 3 || q.Where[2].Opcode != types.SoqlConditionOpcode_Eq {
+		t.Errorf("runParse() = %v", stdout.String())
+	}
+	if strings.Count(stdout.String(), "\n") != 1 {
+		t.Errorf("runParse() -compact = %v lines, want 1", strings.Count(stdout.String(), "\n"))
+	}
+
+	stdout.Reset()
+	if got := runParse([]string{filepath.Join(dir, "b.soql")}, &stdout, &stderr); got != 0 {
+		t.Errorf("runParse() = %v, want 0; %v", got, stderr.String())
+	}
+	var sq types.SoslQuery
+	if err := json.Unmarshal(stdout.Bytes(), &sq); err != nil || len(sq.Returning) != 1 ||
+		!reflect.DeepEqual(sq.Returning[0].From[0].Name, []string{"Contact"}) {
+		t.Errorf("runParse() = %v, %v", stdout.String(), err)
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	if got := runParse([]string{filepath.Join(dir, "c.soql")}, &stdout, &stderr); got != 1 || stdout.Len() != 0 {
+		t.Errorf("runParse() = %v, %v, want 1", got, stdout.String())
+	}
+	if got := runParse([]string{"a.soql", "b.soql"}, &stdout, &stderr); got != 2 {
+		t.Errorf("runParse() = %v, want 2", got)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/shellyln/go-open-soql-parser/soql/parser"
+)
+
+func runParse(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("parse", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	compact := flags.Bool("compact", false, "print the JSON without indentation")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	path, ok := singleFile(flags)
+	if !ok {
+		fmt.Fprintln(stderr, "usage: soql parse [-compact] [file]")
+		return 2
+	}
+
+	text, err := readFile(path)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	var v interface{}
+	q, sq, err := parseQuery(text, parser.ParseOptions{})
+	if err != nil {
+		fmt.Fprintln(stderr, displayName(path)+": "+err.Error())
+		return 1
+	}
+	if sq != nil {
+		v = sq
+	} else {
+		v = q
+	}
+
+	var b []byte
+	if *compact {
+		b, err = json.Marshal(v)
+	} else {
+		b, err = json.MarshalIndent(v, "", "  ")
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	fmt.Fprintln(stdout, string(b))
+	return 0
+}
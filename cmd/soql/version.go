@@ -0,0 +1,8 @@
+package main
+
+var (
+	// Version of distribution
+	Version string
+	// Revision of distribution
+	Revision string
+)
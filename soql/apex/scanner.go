@@ -0,0 +1,244 @@
+// Scanner that extracts the inline SOQL and SOSL queries (e.g. [SELECT Id FROM Account]) from the Apex source.
+package apex
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/shellyln/go-open-soql-parser/soql/parser"
+	"github.com/shellyln/go-open-soql-parser/soql/parser/types"
+)
+
+type QueryKind int
+
+const (
+	QueryKind_Soql QueryKind = iota + 1 // [SELECT ...]
+	QueryKind_Sosl                      // [FIND ...]
+)
+
+func (t QueryKind) String() string {
+	switch t {
+	case QueryKind_Soql:
+		return "Soql"
+	case QueryKind_Sosl:
+		return "Sosl"
+	default:
+		return "Undefined"
+	}
+}
+
+// The inline query found in the Apex source.
+type Query struct {
+	Kind   QueryKind // Soql or Sosl
+	Offset int       // Byte offset of the query text (next to the opening bracket)
+	Line   int       // Line number of the query text (1-based)
+	Col    int       // Column number of the query text (1-based; in runes)
+	Source string    // Query text between the brackets
+}
+
+// The parse result of the inline query.
+type Result struct {
+	Query
+	File string           // File name
+	Soql *types.SoqlQuery // Parsed SOQL query; set if Kind is Soql and there is no error
+	Sosl *types.SoslQuery // Parsed SOSL query; set if Kind is Sosl and there is no error
+	Err  error            // Parse error
+}
+
+// Skip the Apex string literal. s[i] is the opening quote.
+func skipString(s string, i int) int {
+	for i++; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '\'':
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+// Skip the line comment or the block comment if s[i:] starts with it.
+func skipComment(s string, i int) (int, bool) {
+	if i+1 >= len(s) || s[i] != '/' {
+		return i, false
+	}
+	switch s[i+1] {
+	case '/':
+		if j := strings.IndexByte(s[i:], '\n'); j >= 0 {
+			return i + j, true
+		}
+		return len(s), true
+	case '*':
+		if j := strings.Index(s[i+2:], "*/"); j >= 0 {
+			return i + 2 + j + 2, true
+		}
+		return len(s), true
+	}
+	return i, false
+}
+
+func skipSpacesAndComments(s string, i int) int {
+	for i < len(s) {
+		switch s[i] {
+		case ' ', '\t', '\r', '\n', '\v', '\f':
+			i++
+		default:
+			j, ok := skipComment(s, i)
+			if !ok {
+				return i
+			}
+			i = j
+		}
+	}
+	return i
+}
+
+func isIdentChar(c byte) bool {
+	return 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '_' || c == '$'
+}
+
+func hasKeywordAt(s string, i int, keyword string) bool {
+	n := len(keyword)
+	if i+n > len(s) || !strings.EqualFold(s[i:i+n], keyword) {
+		return false
+	}
+	return i+n == len(s) || !isIdentChar(s[i+n])
+}
+
+// Find the closing bracket of the inline query. s[i] is next to the opening bracket.
+// It returns -1 if the query is not terminated.
+func findClosingBracket(s string, i int) int {
+	depth := 0
+	for i < len(s) {
+		switch s[i] {
+		case '\'', '"', '`':
+			// string literal, quoted symbol name and hinting string
+			q := s[i]
+			for i++; i < len(s) && s[i] != q; i++ {
+				if s[i] == '\\' {
+					i++
+				}
+			}
+			i++
+		case '{':
+			// search query of the SOSL
+			for i++; i < len(s) && s[i] != '}'; i++ {
+				if s[i] == '\\' {
+					i++
+				}
+			}
+			i++
+		case '[':
+			depth++
+			i++
+		case ']':
+			if depth == 0 {
+				return i
+			}
+			depth--
+			i++
+		default:
+			if j, ok := skipComment(s, i); ok {
+				i = j
+			} else {
+				i++
+			}
+		}
+	}
+	return -1
+}
+
+// Find the inline queries in the Apex source.
+// Comments and string literals of the Apex code are skipped.
+func Scan(src string) []Query {
+	queries := make([]Query, 0)
+
+	line, lineStart := 1, 0
+	advance := func(from, to int) {
+		for k := from; k < to && k < len(src); k++ {
+			if src[k] == '\n' {
+				line++
+				lineStart = k + 1
+			}
+		}
+	}
+
+	i := 0
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == '\'':
+			j := skipString(src, i)
+			advance(i, j)
+			i = j
+		case c == '/':
+			j, ok := skipComment(src, i)
+			if !ok {
+				j = i + 1
+			}
+			advance(i, j)
+			i = j
+		case c == '[':
+			start := i + 1
+			head := skipSpacesAndComments(src, start)
+
+			var kind QueryKind
+			switch {
+			case hasKeywordAt(src, head, "select"):
+				kind = QueryKind_Soql
+			case hasKeywordAt(src, head, "find"):
+				kind = QueryKind_Sosl
+			}
+
+			end := -1
+			if kind != 0 {
+				end = findClosingBracket(src, start)
+			}
+			if end < 0 {
+				// Not an inline query (e.g. array index) or not terminated.
+				i++
+				continue
+			}
+
+			queries = append(queries, Query{
+				Kind:   kind,
+				Offset: start,
+				Line:   line,
+				Col:    utf8.RuneCountInString(src[lineStart:start]) + 1,
+				Source: src[start:end],
+			})
+
+			advance(i, end+1)
+			i = end + 1
+		default:
+			if c == '\n' {
+				line++
+				lineStart = i + 1
+			}
+			i++
+		}
+	}
+
+	return queries
+}
+
+// Find the inline queries in the Apex source and parse them.
+func ScanAndParse(file, src string) []Result {
+	queries := Scan(src)
+	results := make([]Result, len(queries))
+
+	for i := 0; i < len(queries); i++ {
+		results[i].Query = queries[i]
+		results[i].File = file
+
+		switch queries[i].Kind {
+		case QueryKind_Soql:
+			results[i].Soql, results[i].Err = parser.Parse(queries[i].Source)
+		case QueryKind_Sosl:
+			results[i].Sosl, results[i].Err = parser.ParseSosl(queries[i].Source)
+		}
+	}
+
+	return results
+}
@@ -0,0 +1,81 @@
+package apex_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/shellyln/go-open-soql-parser/soql/apex"
+)
+
+func TestScan(t *testing.T) {
+	type args struct {
+		src string
+	}
+	type query struct {
+		kind   apex.QueryKind
+		line   int
+		col    int
+		source string
+	}
+	tests := []struct {
+		name string
+		args args
+		want []query
+	}{{
+		name: "1",
+		args: args{src: "public class A {\n    List<Account> a = [SELECT Id FROM Account WHERE Id IN :ids[0]];\n}"},
+		want: []query{
+			{kind: apex.QueryKind_Soql, line: 2, col: 24, source: "SELECT Id FROM Account WHERE Id IN :ids[0]"},
+		},
+	}, {
+		name: "comments and strings",
+		args: args{src: "// [SELECT Id FROM A]\n/* [SELECT Id FROM B]\n */ String s = '[SELECT Id FROM C] \\' ';\nx = [ /* c */ select Name FROM D WHERE Name = ']' ]; y = arr[0];"},
+		want: []query{
+			{kind: apex.QueryKind_Soql, line: 4, col: 6, source: " /* c */ select Name FROM D WHERE Name = ']' "},
+		},
+	}, {
+		name: "sosl",
+		args: args{src: "List<List<SObject>> r = [FIND {a\\}]} RETURNING Account(Name)];\nr = [FIND 'x']; r = [finder];"},
+		want: []query{
+			{kind: apex.QueryKind_Sosl, line: 1, col: 26, source: "FIND {a\\}]} RETURNING Account(Name)"},
+			{kind: apex.QueryKind_Sosl, line: 2, col: 6, source: "FIND 'x'"},
+		},
+	}, {
+		name: "not terminated",
+		args: args{src: "x = [SELECT Id FROM A"},
+		want: []query{},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := apex.Scan(tt.args.src)
+			queries := make([]query, len(got))
+			for i, q := range got {
+				queries[i] = query{kind: q.Kind, line: q.Line, col: q.Col, source: q.Source}
+				if tt.args.src[q.Offset:q.Offset+len(q.Source)] != q.Source {
+					t.Errorf("Scan() offset = %v, source %v", q.Offset, q.Source)
+				}
+			}
+			if !reflect.DeepEqual(queries, tt.want) {
+				t.Errorf("Scan() = %v, want %v", queries, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanAndParse(t *testing.T) {
+	src := "a = [SELECT Id FROM Account WHERE Id = :acc.Id];\nb = [SELECT FROM Account];\nc = [FIND {x} RETURNING Contact];"
+	got := apex.ScanAndParse("A.cls", src)
+	if len(got) != 3 {
+		t.Errorf("ScanAndParse() = %v, want 3 results", len(got))
+		return
+	}
+	if got[0].Err != nil || got[0].Soql == nil || got[0].File != "A.cls" {
+		t.Errorf("ScanAndParse() [0] = %v", got[0])
+	}
+	if got[1].Err == nil || got[1].Line != 2 {
+		t.Errorf("ScanAndParse() [1] = %v", got[1])
+	}
+	if got[2].Err != nil || got[2].Sosl == nil || got[2].Line != 3 {
+		t.Errorf("ScanAndParse() [2] = %v", got[2])
+	}
+}
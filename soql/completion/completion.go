@@ -0,0 +1,178 @@
+// Completion engine for the SOQL and SOSL editors.
+// It returns the syntactic context, the allowed keywords and the objects in scope at the cursor.
+// It works on the incomplete and invalid queries.
+package completion
+
+import (
+	"strings"
+
+	"github.com/shellyln/go-open-soql-parser/soql/cst"
+)
+
+type CompletionContext int
+
+const (
+	CompletionContext_Query             CompletionContext = iota + 1 // Start of the query or subquery (SELECT, FIND)
+	CompletionContext_SelectField                                    // Field, function or subquery of the select clause
+	CompletionContext_TypeOfObject                                   // Object type of the TYPEOF ... WHEN branch
+	CompletionContext_Object                                         // Object of the from clause or the SOSL returning clause
+	CompletionContext_ScopeName                                      // Filter scope name of the USING SCOPE clause
+	CompletionContext_ConditionOperand                               // Operand (field or function) of the where or having condition
+	CompletionContext_ConditionOperator                              // Operator of the where or having condition
+	CompletionContext_ConditionValue                                 // Value of the where or having condition
+	CompletionContext_GroupByField                                   // Field or function of the group by clause
+	CompletionContext_OrderByField                                   // Field or function of the order by clause
+	CompletionContext_Number                                         // Number of the limit or offset clause
+	CompletionContext_Clause                                         // After the item; The modifiers of the item or the next clauses
+)
+
+func (t CompletionContext) String() string {
+	switch t {
+	case CompletionContext_Query:
+		return "Query"
+	case CompletionContext_SelectField:
+		return "SelectField"
+	case CompletionContext_TypeOfObject:
+		return "TypeOfObject"
+	case CompletionContext_Object:
+		return "Object"
+	case CompletionContext_ScopeName:
+		return "ScopeName"
+	case CompletionContext_ConditionOperand:
+		return "ConditionOperand"
+	case CompletionContext_ConditionOperator:
+		return "ConditionOperator"
+	case CompletionContext_ConditionValue:
+		return "ConditionValue"
+	case CompletionContext_GroupByField:
+		return "GroupByField"
+	case CompletionContext_OrderByField:
+		return "OrderByField"
+	case CompletionContext_Number:
+		return "Number"
+	case CompletionContext_Clause:
+		return "Clause"
+	default:
+		return "Undefined"
+	}
+}
+
+// Object in scope at the cursor.
+type ScopeObject struct {
+	Name  []string `json:"name"`            // Fully qualified name (object graph path) (e.g. ["Contact", "Account"])
+	Alias string   `json:"alias,omitempty"` // Alias name; Empty if the object has no alias.
+}
+
+// Completion at the cursor.
+type Completion struct {
+	Context         CompletionContext `json:"context"`                   // Syntactic context; 0 if the cursor is in a string literal or a comment.
+	Prefix          string            `json:"prefix"`                    // Partial word before the cursor (e.g. "Acc")
+	Offset          int               `json:"offset"`                    // Start offset of the word to be replaced
+	EndOffset       int               `json:"endOffset"`                 // End offset of the word to be replaced (exclusive)
+	Qualifier       []string          `json:"qualifier,omitempty"`       // Names before the dot as written (e.g. ["c", "Account"] of "c.Account.Na")
+	QualifiedObject []string          `json:"qualifiedObject,omitempty"` // Fully qualified name of the qualifier; nil if it is not resolved.
+	Keywords        []string          `json:"keywords,omitempty"`        // Allowed keywords in upper case; Multiple words are separated by a space (e.g. "ORDER BY").
+	Objects         []ScopeObject     `json:"objects,omitempty"`         // Objects in scope in the order of appearance; The primary object is the first.
+}
+
+func isWord(tok *cst.Token) bool {
+	switch tok.Kind {
+	case cst.TokenKind_Identifier, cst.TokenKind_Keyword, cst.TokenKind_DateLiteral, cst.TokenKind_Number:
+		return true
+	}
+	return false
+}
+
+// Returns true if the token is the unterminated string literal, search query, etc.
+func isUnterminated(tok *cst.Token) bool {
+	return tok.Kind == cst.TokenKind_Unknown && strings.ContainsAny(tok.Text[:1], "'\"`{")
+}
+
+// Returns true if the offset is in the comment or the unterminated comment that ends at the offset.
+func inComment(trivia []cst.Trivia, offset int) bool {
+	for _, t := range trivia {
+		end := t.Offset + len(t.Text)
+		switch t.Kind {
+		case cst.TriviaKind_LineComment:
+			if t.Offset < offset && (offset < end || offset == end && !strings.HasSuffix(t.Text, "\n") && !strings.HasSuffix(t.Text, "\r")) {
+				return true
+			}
+		case cst.TriviaKind_BlockComment:
+			if t.Offset < offset && (offset < end || offset == end && (len(t.Text) < 4 || !strings.HasSuffix(t.Text, "*/"))) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Returns the completion at the offset (byte offset of the cursor) of the source.
+func Complete(s string, offset int) Completion {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > len(s) {
+		offset = len(s)
+	}
+
+	tree, _ := cst.Parse(s)
+	tokens := tree.Tokens
+
+	// Index of the first token that is not before the cursor.
+	cursor := len(tokens)
+	for i, tok := range tokens {
+		if offset <= tok.Offset || offset < tok.End() || offset == tok.End() && (isWord(tok) || isUnterminated(tok)) {
+			cursor = i
+			break
+		}
+	}
+
+	if cursor < len(tokens) {
+		if inComment(tokens[cursor].Leading, offset) {
+			return Completion{}
+		}
+	} else if inComment(tree.Trailing, offset) {
+		return Completion{}
+	}
+
+	z := Completion{
+		Offset:    offset,
+		EndOffset: offset,
+	}
+
+	item := cursor
+	if cursor < len(tokens) && tokens[cursor].Offset < offset {
+		tok := tokens[cursor]
+		if !isWord(tok) {
+			// String literals, bind parameters, hints, etc.
+			return Completion{}
+		}
+		z.Prefix = tok.Text[:offset-tok.Offset]
+		z.Offset = tok.Offset
+		z.EndOffset = tok.End()
+	}
+
+	// Names before the dot (e.g. c.Account.|)
+	for item >= 2 && tokens[item-1].Text == "." && isWord(tokens[item-2]) {
+		item -= 2
+	}
+	if item < cursor {
+		z.Qualifier = make([]string, 0, (cursor-item)/2)
+		for i := item; i < cursor; i += 2 {
+			z.Qualifier = append(z.Qualifier, tokens[i].Text)
+		}
+	}
+
+	w := newWalker(tokens, item)
+	w.walk()
+
+	z.Context, z.Keywords = w.context()
+
+	sc := w.at.scope()
+	z.Objects = sc.objects
+	if z.Qualifier != nil {
+		z.QualifiedObject = sc.resolve(z.Qualifier)
+	}
+
+	return z
+}
@@ -0,0 +1,171 @@
+package completion_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/shellyln/go-open-soql-parser/soql/completion"
+)
+
+func TestComplete(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string // "|" is the cursor
+		want completion.Completion
+	}{{
+		name: "empty",
+		s:    "|",
+		want: completion.Completion{
+			Context:  completion.CompletionContext_Query,
+			Keywords: []string{"SELECT", "FIND"},
+		},
+	}, {
+		name: "select list",
+		s:    "SELECT Id, Acc| FROM Contact",
+		want: completion.Completion{
+			Context:   completion.CompletionContext_SelectField,
+			Prefix:    "Acc",
+			Offset:    11,
+			EndOffset: 14,
+			Keywords:  []string{"TYPEOF"},
+			Objects:   []completion.ScopeObject{{Name: []string{"Contact"}}},
+		},
+	}, {
+		name: "relationship path",
+		s:    "SELECT Id, c.Account.| FROM Contact c",
+		want: completion.Completion{
+			Context:         completion.CompletionContext_SelectField,
+			Offset:          21,
+			EndOffset:       21,
+			Qualifier:       []string{"c", "Account"},
+			QualifiedObject: []string{"Contact", "Account"},
+			Keywords:        []string{"TYPEOF"},
+			Objects:         []completion.ScopeObject{{Name: []string{"Contact"}, Alias: "c"}},
+		},
+	}, {
+		name: "after field",
+		s:    "SELECT Id |",
+		want: completion.Completion{
+			Context:   completion.CompletionContext_Clause,
+			Offset:    10,
+			EndOffset: 10,
+			Keywords:  []string{"FROM"},
+		},
+	}, {
+		name: "from",
+		s:    "SELECT Id FROM Contact c, c.Account a, Owner |",
+		want: completion.Completion{
+			Context:   completion.CompletionContext_Clause,
+			Offset:    45,
+			EndOffset: 45,
+			Keywords:  []string{"USING SCOPE", "WHERE", "WITH", "GROUP BY", "ORDER BY", "LIMIT", "OFFSET", "FOR", "ALL ROWS"},
+			Objects: []completion.ScopeObject{
+				{Name: []string{"Contact"}, Alias: "c"},
+				{Name: []string{"Contact", "Account"}, Alias: "a"},
+				{Name: []string{"Contact", "Owner"}},
+			},
+		},
+	}, {
+		name: "where operand",
+		s:    "SELECT Id FROM Contact WHERE Name = 'a' AND |",
+		want: completion.Completion{
+			Context:   completion.CompletionContext_ConditionOperand,
+			Offset:    44,
+			EndOffset: 44,
+			Keywords:  []string{"NOT"},
+			Objects:   []completion.ScopeObject{{Name: []string{"Contact"}}},
+		},
+	}, {
+		name: "operator",
+		s:    "SELECT Id FROM Contact WHERE Account.Name |",
+		want: completion.Completion{
+			Context:   completion.CompletionContext_ConditionOperator,
+			Offset:    42,
+			EndOffset: 42,
+			Keywords:  []string{"LIKE", "NOT LIKE", "IN", "NOT IN", "INCLUDES", "EXCLUDES"},
+			Objects:   []completion.ScopeObject{{Name: []string{"Contact"}}, {Name: []string{"Contact", "Account"}}},
+		},
+	}, {
+		name: "value list",
+		s:    "SELECT Id FROM Contact WHERE Id IN (|",
+		want: completion.Completion{
+			Context:   completion.CompletionContext_ConditionValue,
+			Offset:    36,
+			EndOffset: 36,
+			Keywords:  []string{"SELECT"},
+			Objects:   []completion.ScopeObject{{Name: []string{"Contact"}}},
+		},
+	}, {
+		name: "after condition",
+		s:    "SELECT Id FROM Contact WHERE (Name = 'a' OR Name = 'b') |",
+		want: completion.Completion{
+			Context:   completion.CompletionContext_Clause,
+			Offset:    56,
+			EndOffset: 56,
+			Keywords:  []string{"AND", "OR", "WITH", "GROUP BY", "ORDER BY", "LIMIT", "OFFSET", "FOR", "ALL ROWS"},
+			Objects:   []completion.ScopeObject{{Name: []string{"Contact"}}},
+		},
+	}, {
+		name: "subquery",
+		s:    "SELECT Name, (SELECT Id FROM Contacts c WHERE c.Owner.N|) FROM Account",
+		want: completion.Completion{
+			Context:         completion.CompletionContext_ConditionOperand,
+			Prefix:          "N",
+			Offset:          54,
+			EndOffset:       55,
+			Qualifier:       []string{"c", "Owner"},
+			QualifiedObject: []string{"Account", "Contacts", "Owner"},
+			Keywords:        []string{"NOT"},
+			Objects: []completion.ScopeObject{
+				{Name: []string{"Account", "Contacts"}, Alias: "c"},
+				{Name: []string{"Account", "Contacts", "Owner"}},
+			},
+		},
+	}, {
+		name: "order by",
+		s:    "SELECT Id FROM Contact ORDER BY Name DESC |",
+		want: completion.Completion{
+			Context:   completion.CompletionContext_Clause,
+			Offset:    42,
+			EndOffset: 42,
+			Keywords:  []string{"NULLS FIRST", "NULLS LAST", "LIMIT", "OFFSET", "FOR", "ALL ROWS"},
+			Objects:   []completion.ScopeObject{{Name: []string{"Contact"}}},
+		},
+	}, {
+		name: "typeof",
+		s:    "SELECT TYPEOF What WHEN |",
+		want: completion.Completion{
+			Context:   completion.CompletionContext_TypeOfObject,
+			Offset:    24,
+			EndOffset: 24,
+		},
+	}, {
+		name: "sosl",
+		s:    "FIND {a} RETURNING Account(Id |",
+		want: completion.Completion{
+			Context:   completion.CompletionContext_Clause,
+			Offset:    30,
+			EndOffset: 30,
+			Keywords:  []string{"WHERE", "ORDER BY", "LIMIT", "OFFSET"},
+			Objects:   []completion.ScopeObject{{Name: []string{"Account"}}},
+		},
+	}, {
+		name: "string",
+		s:    "SELECT Id FROM Contact WHERE Name = 'a|'",
+		want: completion.Completion{},
+	}, {
+		name: "comment",
+		s:    "SELECT Id -- |",
+		want: completion.Completion{},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			offset := strings.Index(tt.s, "|")
+			got := completion.Complete(strings.Replace(tt.s, "|", "", 1), offset)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Complete() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,404 @@
+package completion
+
+import (
+	"strings"
+
+	"github.com/shellyln/go-open-soql-parser/soql/cst"
+)
+
+var (
+	operatorKeywords = []string{"LIKE", "NOT LIKE", "IN", "NOT IN", "INCLUDES", "EXCLUDES"}
+	valueKeywords    = []string{
+		"NULL", "TRUE", "FALSE",
+		"YESTERDAY", "TODAY", "TOMORROW",
+		"LAST_WEEK", "THIS_WEEK", "NEXT_WEEK",
+		"LAST_MONTH", "THIS_MONTH", "NEXT_MONTH",
+		"LAST_90_DAYS", "NEXT_90_DAYS",
+		"THIS_QUARTER", "LAST_QUARTER", "NEXT_QUARTER",
+		"THIS_YEAR", "LAST_YEAR", "NEXT_YEAR",
+		"THIS_FISCAL_QUARTER", "LAST_FISCAL_QUARTER", "NEXT_FISCAL_QUARTER",
+		"THIS_FISCAL_YEAR", "LAST_FISCAL_YEAR", "NEXT_FISCAL_YEAR",
+	}
+	scopeNames = []string{"DELEGATED", "EVERYTHING", "MINE", "MINE_AND_MY_GROUPS", "MY_TERRITORY", "MY_TEAM_TERRITORY", "TEAM"}
+)
+
+// Returns the keywords of the clauses that can follow the clause.
+func nextClauses(f *frame, clause string) []string {
+	z := make([]string, 0, len(f.clauses))
+	found := false
+	for _, c := range f.clauses {
+		if !found {
+			found = c == clause
+			continue
+		}
+		switch {
+		case c == "using scope":
+			continue
+		case c == "having" && clause != "group by":
+			continue
+		case c == "all rows" && f.parent != nil:
+			continue
+		}
+		z = append(z, strings.ToUpper(c))
+	}
+	return z
+}
+
+func withKeywords(keywords []string, next []string) []string {
+	z := make([]string, 0, len(keywords)+len(next))
+	z = append(z, keywords...)
+	z = append(z, next...)
+	return z
+}
+
+// Returns the last keyword in kws at or before i at the same nesting level; "" if not found.
+func (w *walker) lastKeyword(i int, kws ...string) string {
+	depth := 0
+	for ; i >= 0; i-- {
+		switch w.textAt(i) {
+		case ")":
+			depth++
+			continue
+		case "(":
+			if depth == 0 {
+				return ""
+			}
+			depth--
+			continue
+		}
+		if depth > 0 {
+			continue
+		}
+		kw := w.keywordAt(i)
+		for _, k := range kws {
+			if kw == k {
+				return kw
+			}
+		}
+	}
+	return ""
+}
+
+// Returns the index of the unclosed opening parenthesis at or before i; -1 if not found.
+func (w *walker) openParen(i int) int {
+	depth := 0
+	for ; i >= 0; i-- {
+		switch w.textAt(i) {
+		case ")":
+			depth++
+		case "(":
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+	return -1
+}
+
+func (w *walker) isComparison(i int) bool {
+	if i < 0 || len(w.tokens) <= i {
+		return false
+	}
+	tok := w.tokens[i]
+	switch tok.Kind {
+	case cst.TokenKind_Operator:
+		switch tok.Text {
+		case "=", "!=", "<>", "<", ">", "<=", ">=":
+			return true
+		}
+	case cst.TokenKind_Keyword:
+		switch w.keywordAt(i) {
+		case "like", "in", "includes", "excludes":
+			return true
+		}
+	}
+	return false
+}
+
+// Returns true if the condition that includes i has the comparison operator at or before i.
+// The condition in the parentheses (e.g. (a = 1 OR b = 2)) is also a complete condition.
+func (w *walker) hasComparison(i int) bool {
+	depth := 0
+	for ; i >= 0; i-- {
+		switch w.textAt(i) {
+		case ")":
+			depth++
+			continue
+		case "(":
+			if depth == 0 {
+				return false
+			}
+			depth--
+			if depth == 0 && !(i > 0 && w.tokens[i-1].Kind == cst.TokenKind_Identifier || w.isComparison(i-1)) {
+				// Not the function call nor the list
+				return true
+			}
+			continue
+		}
+		if depth > 0 {
+			continue
+		}
+		if w.isComparison(i) {
+			return true
+		}
+		switch w.keywordAt(i) {
+		case "and", "or", "not", "where", "having":
+			return false
+		}
+	}
+	return false
+}
+
+// Returns the syntactic context and the allowed keywords at the cursor.
+func (w *walker) context() (CompletionContext, []string) {
+	f, clause, depth := w.snap.frame, w.snap.clause, w.snap.depth
+
+	switch clause {
+	case "":
+		if f.parent == nil && w.item == 0 {
+			return CompletionContext_Query, []string{"SELECT", "FIND"}
+		}
+		return CompletionContext_Query, []string{"SELECT"}
+	case "select":
+		return w.selectContext(f, depth)
+	case "from", "using scope":
+		return w.fromContext(f, clause, depth)
+	case "where", "having":
+		return w.conditionContext(f, clause, depth)
+	case "with":
+		return w.withContext(f)
+	case "group by":
+		return w.groupByContext(f, depth)
+	case "order by":
+		return w.orderByContext(f, depth)
+	}
+
+	p := w.item - 1
+	kw := w.keywordAt(p)
+
+	switch clause {
+	case "limit", "offset":
+		if kw == clause {
+			return CompletionContext_Number, nil
+		}
+	case "for":
+		switch kw {
+		case "for":
+			return CompletionContext_Clause, []string{"VIEW", "REFERENCE", "UPDATE"}
+		case "update":
+			return CompletionContext_Clause, []string{"TRACKING", "VIEWSTAT"}
+		}
+	case "update":
+		if kw == "update" {
+			return CompletionContext_Clause, []string{"TRACKING", "VIEWSTAT"}
+		}
+		return CompletionContext_Clause, nil
+	case "all rows":
+		return CompletionContext_Clause, nil
+	case "find":
+		if kw == "find" {
+			// The search query ({...}) is expected.
+			return CompletionContext_Clause, nil
+		}
+	case "in":
+		switch strings.ToLower(w.textAt(p)) {
+		case "in":
+			return CompletionContext_Clause, []string{"ALL FIELDS", "NAME FIELDS", "EMAIL FIELDS", "PHONE FIELDS", "SIDEBAR FIELDS"}
+		case "all", "name", "email", "phone", "sidebar":
+			return CompletionContext_Clause, []string{"FIELDS"}
+		}
+	case "returning":
+		if kw == "returning" || w.textAt(p) == "," && depth == 0 {
+			return CompletionContext_Object, nil
+		}
+		if depth > 0 {
+			return CompletionContext_Clause, nil
+		}
+	}
+	return CompletionContext_Clause, nextClauses(f, clause)
+}
+
+func (w *walker) selectContext(f *frame, depth int) (CompletionContext, []string) {
+	p := w.item - 1
+	kw, text := w.keywordAt(p), w.textAt(p)
+
+	typeOf := w.lastKeyword(p, "select", "typeof", "when", "then", "else", "end")
+	inTypeOf := typeOf != "" && typeOf != "select" && typeOf != "end"
+
+	switch {
+	case kw == "select" || text == "," && depth == 0 && !inTypeOf:
+		if f.returning {
+			return CompletionContext_SelectField, nil
+		}
+		return CompletionContext_SelectField, []string{"TYPEOF"}
+	case text == "(":
+		if w.keywordAt(p-1) == "select" || w.textAt(p-1) == "," {
+			// Subquery
+			return CompletionContext_Query, []string{"SELECT"}
+		}
+		return CompletionContext_SelectField, nil
+	case text == "," || kw == "typeof" || kw == "then" || kw == "else":
+		return CompletionContext_SelectField, nil
+	case kw == "when":
+		return CompletionContext_TypeOfObject, nil
+	case inTypeOf:
+		switch typeOf {
+		case "typeof":
+			return CompletionContext_Clause, []string{"WHEN"}
+		case "when":
+			return CompletionContext_Clause, []string{"THEN"}
+		case "then":
+			return CompletionContext_Clause, []string{"WHEN", "ELSE", "END"}
+		default:
+			return CompletionContext_Clause, []string{"END"}
+		}
+	case depth > 0:
+		return CompletionContext_Clause, nil
+	case f.returning:
+		return CompletionContext_Clause, nextClauses(f, "select")
+	}
+	return CompletionContext_Clause, []string{"FROM"}
+}
+
+func (w *walker) fromContext(f *frame, clause string, depth int) (CompletionContext, []string) {
+	p := w.item - 1
+	kw, text := w.keywordAt(p), w.textAt(p)
+
+	switch {
+	case kw == "from" || text == "," && depth == 0:
+		return CompletionContext_Object, nil
+	case kw == "using":
+		return CompletionContext_Clause, []string{"SCOPE"}
+	case kw == "scope":
+		return CompletionContext_ScopeName, scopeNames
+	case clause == "using scope":
+		return CompletionContext_Clause, nextClauses(f, "from")
+	}
+	return CompletionContext_Clause, withKeywords([]string{"USING SCOPE"}, nextClauses(f, "from"))
+}
+
+func (w *walker) conditionContext(f *frame, clause string, depth int) (CompletionContext, []string) {
+	p := w.item - 1
+	kw, text := w.keywordAt(p), w.textAt(p)
+
+	switch {
+	case kw == "where" || kw == "having" || kw == "and" || kw == "or":
+		return CompletionContext_ConditionOperand, []string{"NOT"}
+	case kw == "not":
+		switch k := w.keywordAt(p - 1); {
+		case k == "where" || k == "having" || k == "and" || k == "or" || w.textAt(p-1) == "(":
+			return CompletionContext_ConditionOperand, nil
+		}
+		// NOT IN, NOT LIKE
+		return CompletionContext_ConditionOperator, []string{"IN", "LIKE"}
+	case text == "(":
+		switch k := w.keywordAt(p - 1); {
+		case k == "in" || k == "includes" || k == "excludes":
+			return CompletionContext_ConditionValue, []string{"SELECT"}
+		case p > 0 && w.tokens[p-1].Kind == cst.TokenKind_Identifier:
+			// Parameters of the function
+			if w.hasComparison(p - 1) {
+				return CompletionContext_ConditionValue, nil
+			}
+			return CompletionContext_ConditionOperand, nil
+		}
+		return CompletionContext_ConditionOperand, []string{"NOT"}
+	case text == ",":
+		open := w.openParen(p)
+		switch k := w.keywordAt(open - 1); {
+		case k == "in" || k == "includes" || k == "excludes":
+			return CompletionContext_ConditionValue, valueKeywords
+		case open > 0 && w.hasComparison(open-1):
+			return CompletionContext_ConditionValue, nil
+		}
+		return CompletionContext_ConditionOperand, nil
+	case kw == "like":
+		return CompletionContext_ConditionValue, nil
+	case kw == "in" || kw == "includes" || kw == "excludes":
+		// The list or the subquery in the parentheses, or the bind parameter is expected.
+		return CompletionContext_ConditionValue, nil
+	case w.isComparison(p):
+		return CompletionContext_ConditionValue, valueKeywords
+	case p >= 0 && w.tokens[p].Kind == cst.TokenKind_Operator:
+		// Arithmetic and concatenation operators
+		if w.hasComparison(p) {
+			return CompletionContext_ConditionValue, nil
+		}
+		return CompletionContext_ConditionOperand, nil
+	}
+
+	if !w.hasComparison(p) {
+		return CompletionContext_ConditionOperator, operatorKeywords
+	}
+	if depth > 0 {
+		return CompletionContext_Clause, []string{"AND", "OR"}
+	}
+	return CompletionContext_Clause, withKeywords([]string{"AND", "OR"}, nextClauses(f, clause))
+}
+
+func (w *walker) withContext(f *frame) (CompletionContext, []string) {
+	p := w.item - 1
+	kw, text := w.keywordAt(p), w.textAt(p)
+
+	if f.clauses[0] == "find" {
+		// SOSL
+		if kw == "with" {
+			return CompletionContext_Clause, nil
+		}
+		return CompletionContext_Clause, nextClauses(f, "with")
+	}
+
+	switch {
+	case kw == "with":
+		return CompletionContext_Clause, []string{"SECURITY_ENFORCED", "USER_MODE", "SYSTEM_MODE", "DATA CATEGORY"}
+	case kw == "data":
+		return CompletionContext_Clause, []string{"CATEGORY"}
+	case kw == "category" || kw == "and" || kw == "at" || kw == "above" || kw == "below" || kw == "above_or_below" ||
+		text == "(" || text == ",":
+		// Data category group name or data category name
+		return CompletionContext_Clause, nil
+	case p >= 0 && w.tokens[p].Kind == cst.TokenKind_Identifier:
+		if k := w.keywordAt(p - 1); k == "category" || k == "and" {
+			return CompletionContext_Clause, []string{"AT", "ABOVE", "BELOW", "ABOVE_OR_BELOW"}
+		}
+		return CompletionContext_Clause, withKeywords([]string{"AND"}, nextClauses(f, "with"))
+	case text == ")":
+		return CompletionContext_Clause, withKeywords([]string{"AND"}, nextClauses(f, "with"))
+	}
+	return CompletionContext_Clause, nextClauses(f, "with")
+}
+
+func (w *walker) groupByContext(f *frame, depth int) (CompletionContext, []string) {
+	p := w.item - 1
+	kw, text := w.keywordAt(p), w.textAt(p)
+
+	switch {
+	case kw == "by":
+		return CompletionContext_GroupByField, []string{"ROLLUP", "CUBE"}
+	case text == "," || text == "(":
+		return CompletionContext_GroupByField, nil
+	case depth > 0:
+		return CompletionContext_Clause, nil
+	}
+	return CompletionContext_Clause, nextClauses(f, "group by")
+}
+
+func (w *walker) orderByContext(f *frame, depth int) (CompletionContext, []string) {
+	p := w.item - 1
+	kw, text := w.keywordAt(p), w.textAt(p)
+
+	switch {
+	case kw == "by" || text == "," || text == "(":
+		return CompletionContext_OrderByField, nil
+	case depth > 0:
+		return CompletionContext_Clause, nil
+	case kw == "nulls":
+		return CompletionContext_Clause, []string{"FIRST", "LAST"}
+	case kw == "asc" || kw == "desc":
+		return CompletionContext_Clause, withKeywords([]string{"NULLS FIRST", "NULLS LAST"}, nextClauses(f, "order by"))
+	case kw == "first" || kw == "last":
+		return CompletionContext_Clause, nextClauses(f, "order by")
+	}
+	return CompletionContext_Clause, withKeywords([]string{"ASC", "DESC", "NULLS FIRST", "NULLS LAST"}, nextClauses(f, "order by"))
+}
@@ -0,0 +1,251 @@
+package completion
+
+import (
+	"strings"
+
+	"github.com/shellyln/go-nameutil/nameutil"
+	"github.com/shellyln/go-open-soql-parser/soql/cst"
+)
+
+var (
+	soqlClauses      = []string{"select", "from", "using scope", "where", "with", "group by", "having", "order by", "limit", "offset", "for", "all rows"}
+	soslClauses      = []string{"find", "in", "returning", "with", "limit", "update"}
+	returningClauses = []string{"select", "where", "order by", "limit", "offset"}
+)
+
+type objectRef struct {
+	name  []string
+	alias string
+}
+
+// Query, subquery or field list of the SOSL returning object.
+type frame struct {
+	parent    *frame
+	relation  bool        // Subquery of the select clause; The objects are the relationships of the parent.
+	returning bool        // Field list of the SOSL returning object
+	clauses   []string    // Clauses in the order
+	clause    string      // Current clause
+	depth     int         // Nesting level of the parentheses in the frame
+	objects   []objectRef // Objects of the from clause or the SOSL returning object
+	paths     [][]string  // Relationship paths referenced in the frame (names before the last dot)
+}
+
+// State of the frame at the cursor.
+type snapshot struct {
+	frame  *frame
+	clause string
+	depth  int
+}
+
+type walker struct {
+	tokens []*cst.Token
+	item   int // Index of the first token of the item at the cursor
+	at     *frame
+	snap   snapshot
+}
+
+func newWalker(tokens []*cst.Token, item int) *walker {
+	return &walker{
+		tokens: tokens,
+		item:   item,
+	}
+}
+
+func (w *walker) keywordAt(i int) string {
+	if i < 0 || len(w.tokens) <= i || w.tokens[i].Kind != cst.TokenKind_Keyword {
+		return ""
+	}
+	return strings.ToLower(w.tokens[i].Text)
+}
+
+func (w *walker) textAt(i int) string {
+	if i < 0 || len(w.tokens) <= i {
+		return ""
+	}
+	return w.tokens[i].Text
+}
+
+// Returns the dotted name at i and the index of the next token.
+func (w *walker) nameAt(i int) ([]string, int) {
+	name := []string{w.tokens[i].Text}
+	i++
+	for i+1 < len(w.tokens) && w.tokens[i].Text == "." && isWord(w.tokens[i+1]) && w.tokens[i].End() == w.tokens[i+1].Offset {
+		name = append(name, w.tokens[i+1].Text)
+		i += 2
+	}
+	return name, i
+}
+
+// Walk all tokens to collect the frames and their objects, and take the snapshot at the cursor.
+func (w *walker) walk() {
+	f := &frame{clauses: soqlClauses}
+	if w.keywordAt(0) == "find" {
+		f.clauses = soslClauses
+	}
+
+	for i := 0; i < len(w.tokens); {
+		if w.at == nil && w.item <= i {
+			// The frame and the clause are not changed within the name that includes the cursor.
+			w.at = f
+			w.snap = snapshot{frame: f, clause: f.clause, depth: f.depth}
+		}
+
+		tok := w.tokens[i]
+		switch {
+		case tok.Text == "(":
+			switch {
+			case w.keywordAt(i+1) == "select":
+				f = &frame{parent: f, relation: f.clause == "select", clauses: soqlClauses}
+			case f.clause == "returning" && f.depth == 0 && i > 0 && isWord(w.tokens[i-1]):
+				name := []string{w.tokens[i-1].Text}
+				f = &frame{parent: f, returning: true, clauses: returningClauses, clause: "select",
+					objects: []objectRef{{name: name}}}
+			default:
+				f.depth++
+			}
+		case tok.Text == ")":
+			switch {
+			case f.depth > 0:
+				f.depth--
+			case f.parent != nil:
+				f = f.parent
+			}
+		case tok.Text == "," && f.clause == "using scope" && f.depth == 0:
+			// The next object of the from clause.
+			f.clause = "from"
+		case tok.Kind == cst.TokenKind_Keyword:
+			w.keyword(f, i)
+		case tok.Kind == cst.TokenKind_Identifier && w.textAt(i-1) != ".":
+			name, next := w.nameAt(i)
+			switch {
+			case f.clause == "from" && f.depth == 0:
+				ref := objectRef{name: name}
+				if next < len(w.tokens) && w.tokens[next].Kind == cst.TokenKind_Identifier {
+					if next != w.item {
+						ref.alias = w.tokens[next].Text
+					}
+					next++
+				}
+				if !(i <= w.item && w.item < i+len(name)*2-1) {
+					// The object that is being typed is not in scope.
+					f.objects = append(f.objects, ref)
+				}
+			case len(name) > 1:
+				f.paths = append(f.paths, name[:len(name)-1])
+			}
+			i = next
+			continue
+		}
+		i++
+	}
+
+	if w.at == nil {
+		w.at = f
+		w.snap = snapshot{frame: f, clause: f.clause, depth: f.depth}
+	}
+}
+
+// Change the current clause of the frame by the keyword at i.
+func (w *walker) keyword(f *frame, i int) {
+	switch kw := w.keywordAt(i); kw {
+	case "select", "from", "where", "having", "with", "limit", "offset", "find", "returning":
+		f.clause = kw
+	case "group", "order":
+		if w.keywordAt(i+1) == "by" {
+			f.clause = kw + " by"
+		}
+	case "using":
+		f.clause = "using scope"
+	case "for":
+		f.clause = kw
+	case "update":
+		if f.clause != "for" {
+			f.clause = kw
+		}
+	case "in":
+		if f.clause == "find" {
+			f.clause = kw
+		}
+	case "all":
+		if w.keywordAt(i+1) == "rows" {
+			f.clause = "all rows"
+		}
+	}
+}
+
+// Objects in scope of the frame and the name resolution map.
+type scope struct {
+	names   map[string][]string // Dotted name (include alias) -> fully qualified name
+	objects []ScopeObject
+	primary []string
+}
+
+func (sc *scope) add(name []string, alias string) {
+	for i := 1; i <= len(name); i++ {
+		key := nameutil.MakeDottedKeyIgnoreCase(name, i)
+		if _, ok := sc.names[key]; !ok {
+			sc.names[key] = name[:i]
+			sc.objects = append(sc.objects, ScopeObject{Name: name[:i]})
+		}
+	}
+	if alias != "" {
+		sc.names[nameutil.MakeDottedKeyIgnoreCase([]string{alias}, 1)] = name
+		key := nameutil.MakeDottedKeyIgnoreCase(name, len(name))
+		for i := 0; i < len(sc.objects); i++ {
+			if nameutil.MakeDottedKeyIgnoreCase(sc.objects[i].Name, len(sc.objects[i].Name)) == key {
+				sc.objects[i].Alias = alias
+			}
+		}
+	}
+}
+
+// Returns the fully qualified name of the name.
+// If the first element is not an alias nor a known object, the name is qualified by the primary object.
+func (sc *scope) resolve(name []string) []string {
+	if len(name) == 0 || sc.primary == nil {
+		return nil
+	}
+	s := make([]string, 0, len(sc.primary)+len(name))
+	if fqn, ok := sc.names[nameutil.MakeDottedKeyIgnoreCase(name, 1)]; ok {
+		s = append(s, fqn...)
+	} else {
+		s = append(s, sc.primary...)
+		s = append(s, name[0])
+	}
+	s = append(s, name[1:]...)
+	return s
+}
+
+// Returns the scope of the frame.
+// The subquery of the select clause inherits the names of the parent,
+// and its primary object is qualified by the primary object of the parent.
+func (f *frame) scope() *scope {
+	sc := &scope{names: make(map[string][]string)}
+
+	if f.relation && f.parent != nil {
+		parent := f.parent.scope()
+		for k, v := range parent.names {
+			sc.names[k] = v
+		}
+		sc.primary = parent.primary
+	}
+
+	for i, obj := range f.objects {
+		fqn := sc.resolve(obj.name)
+		if fqn == nil {
+			fqn = obj.name
+		}
+		if i == 0 {
+			sc.primary = fqn
+		}
+		sc.add(fqn, obj.alias)
+	}
+
+	for _, path := range f.paths {
+		if fqn := sc.resolve(path); fqn != nil {
+			sc.add(fqn, "")
+		}
+	}
+
+	return sc
+}
@@ -0,0 +1,96 @@
+// Lossless concrete syntax tree of the SOQL and SOSL query.
+// The tokens keep the original text and the whitespaces and comments (trivia),
+// and each token is linked to the node of the parsed query that is built from it.
+package cst
+
+import (
+	"strings"
+
+	"github.com/shellyln/go-open-soql-parser/soql/parser"
+	. "github.com/shellyln/go-open-soql-parser/soql/parser/types"
+)
+
+// Concrete syntax tree.
+type Tree struct {
+	Source   string     // Original source
+	Tokens   []*Token   // Tokens in the source order
+	Trailing []Trivia   // Whitespaces and comments after the last token
+	Query    *SoqlQuery // Parsed SOQL query; nil if the source is SOSL or it has errors
+	Search   *SoslQuery // Parsed SOSL query; nil if the source is SOQL or it has errors
+}
+
+// Reconstruct the source from the tokens and the trivia.
+// If no token text is replaced, it is the same as the original source.
+func (t *Tree) String() string {
+	var sb strings.Builder
+	for _, tok := range t.Tokens {
+		for _, trivia := range tok.Leading {
+			sb.WriteString(trivia.Text)
+		}
+		sb.WriteString(tok.Text)
+	}
+	for _, trivia := range t.Trailing {
+		sb.WriteString(trivia.Text)
+	}
+	return sb.String()
+}
+
+// Returns the tokens that are linked to the node (not including the tokens of the child nodes).
+// node is the pointer to the node in the Query or the Search (e.g. &tree.Query.Fields[0]).
+func (t *Tree) TokensOf(node interface{}) []*Token {
+	z := make([]*Token, 0)
+	for _, tok := range t.Tokens {
+		if tok.Node == node {
+			z = append(z, tok)
+		}
+	}
+	return z
+}
+
+// Returns the token at the offset of the original source, or nil if the offset is in the trivia.
+func (t *Tree) TokenAt(offset int) *Token {
+	for _, tok := range t.Tokens {
+		if tok.Offset <= offset && offset < tok.End() {
+			return tok
+		}
+	}
+	return nil
+}
+
+func isSearch(tokens []*Token) bool {
+	return len(tokens) != 0 && strings.EqualFold(tokens[0].Text, "find")
+}
+
+// Parse the source into the concrete syntax tree.
+// If the source has errors, the tree that has no query (tokens only) and the error are returned.
+func Parse(s string) (*Tree, error) {
+	tokens, trailing := Lex(s)
+	tree := &Tree{
+		Source:   s,
+		Tokens:   tokens,
+		Trailing: trailing,
+	}
+
+	var err error
+	if isSearch(tokens) {
+		tree.Search, err = parser.ParseSosl(s)
+	} else {
+		tree.Query, err = parser.Parse(s)
+	}
+	if err != nil {
+		return tree, err
+	}
+
+	nodes := make([]node, 0)
+	if tree.Query != nil {
+		nodes = collectQueryNodes(nodes, tree.Query)
+	}
+	if tree.Search != nil {
+		for i := 0; i < len(tree.Search.Returning); i++ {
+			nodes = collectQueryNodes(nodes, &tree.Search.Returning[i])
+		}
+	}
+	linkTokens(tokens, nodes)
+
+	return tree, nil
+}
@@ -0,0 +1,308 @@
+package cst_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/shellyln/go-open-soql-parser/soql/cst"
+	"github.com/shellyln/go-open-soql-parser/soql/parser/core/class"
+	"github.com/shellyln/go-open-soql-parser/soql/parser/types"
+)
+
+func TestLex(t *testing.T) {
+	type token struct {
+		kind cst.TokenKind
+		text string
+	}
+	tests := []struct {
+		name     string
+		s        string
+		want     []token
+		trailing int
+	}{{
+		name: "1",
+		s:    "SELECT Id, COUNT(Name) -- c\nFROM Account /* x */",
+		want: []token{
+			{cst.TokenKind_Keyword, "SELECT"},
+			{cst.TokenKind_Identifier, "Id"},
+			{cst.TokenKind_Punctuation, ","},
+			{cst.TokenKind_Identifier, "COUNT"},
+			{cst.TokenKind_Punctuation, "("},
+			{cst.TokenKind_Identifier, "Name"},
+			{cst.TokenKind_Punctuation, ")"},
+			{cst.TokenKind_Keyword, "FROM"},
+			{cst.TokenKind_Identifier, "Account"},
+		},
+		trailing: 2,
+	}, {
+		name: "literals",
+		s:    "a = 'it\\'s' AND b >= 1.5e3 AND c = 2023-01-02 AND d < 2023-01-02T03:04:05+09:00 AND e = LAST_N_DAYS:3 AND f = TODAY AND g = USD5.5 AND h IN :ids[0]",
+		want: []token{
+			{cst.TokenKind_Identifier, "a"},
+			{cst.TokenKind_Operator, "="},
+			{cst.TokenKind_String, "'it\\'s'"},
+			{cst.TokenKind_Keyword, "AND"},
+			{cst.TokenKind_Identifier, "b"},
+			{cst.TokenKind_Operator, ">="},
+			{cst.TokenKind_Number, "1.5e3"},
+			{cst.TokenKind_Keyword, "AND"},
+			{cst.TokenKind_Identifier, "c"},
+			{cst.TokenKind_Operator, "="},
+			{cst.TokenKind_DateTime, "2023-01-02"},
+			{cst.TokenKind_Keyword, "AND"},
+			{cst.TokenKind_Identifier, "d"},
+			{cst.TokenKind_Operator, "<"},
+			{cst.TokenKind_DateTime, "2023-01-02T03:04:05+09:00"},
+			{cst.TokenKind_Keyword, "AND"},
+			{cst.TokenKind_Identifier, "e"},
+			{cst.TokenKind_Operator, "="},
+			{cst.TokenKind_DateLiteral, "LAST_N_DAYS:3"},
+			{cst.TokenKind_Keyword, "AND"},
+			{cst.TokenKind_Identifier, "f"},
+			{cst.TokenKind_Operator, "="},
+			{cst.TokenKind_DateLiteral, "TODAY"},
+			{cst.TokenKind_Keyword, "AND"},
+			{cst.TokenKind_Identifier, "g"},
+			{cst.TokenKind_Operator, "="},
+			{cst.TokenKind_Number, "USD5.5"},
+			{cst.TokenKind_Keyword, "AND"},
+			{cst.TokenKind_Identifier, "h"},
+			{cst.TokenKind_Keyword, "IN"},
+			{cst.TokenKind_Parameter, ":ids[0]"},
+		},
+	}, {
+		name: "names after the dot",
+		s:    "SELECT Account.Limit, Owner. FROM Account",
+		want: []token{
+			{cst.TokenKind_Keyword, "SELECT"},
+			{cst.TokenKind_Identifier, "Account"},
+			{cst.TokenKind_Punctuation, "."},
+			{cst.TokenKind_Identifier, "Limit"},
+			{cst.TokenKind_Punctuation, ","},
+			{cst.TokenKind_Identifier, "Owner"},
+			{cst.TokenKind_Punctuation, "."},
+			{cst.TokenKind_Keyword, "FROM"},
+			{cst.TokenKind_Identifier, "Account"},
+		},
+	}, {
+		name: "currency-like identifier",
+		s:    "a = ABC123 AND b = USD1x",
+		want: []token{
+			{cst.TokenKind_Identifier, "a"},
+			{cst.TokenKind_Operator, "="},
+			{cst.TokenKind_Identifier, "ABC123"},
+			{cst.TokenKind_Keyword, "AND"},
+			{cst.TokenKind_Identifier, "b"},
+			{cst.TokenKind_Operator, "="},
+			{cst.TokenKind_Identifier, "USD1x"},
+		},
+	}, {
+		name: "invalid",
+		s:    "SELECT # 'abc",
+		want: []token{
+			{cst.TokenKind_Keyword, "SELECT"},
+			{cst.TokenKind_Unknown, "#"},
+			{cst.TokenKind_Unknown, "'abc"},
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, trailing := cst.Lex(tt.s)
+			if len(tokens) != len(tt.want) {
+				t.Errorf("Lex() = %v tokens, want %v", len(tokens), len(tt.want))
+				return
+			}
+			for i, tok := range tokens {
+				if tok.Kind != tt.want[i].kind || tok.Text != tt.want[i].text {
+					t.Errorf("Lex() [%v] = %v %q, want %v %q", i, tok.Kind, tok.Text, tt.want[i].kind, tt.want[i].text)
+				}
+				if tt.s[tok.Offset:tok.End()] != tok.Text {
+					t.Errorf("Lex() [%v] offset = %v", i, tok.Offset)
+				}
+			}
+			if len(trailing) != tt.trailing {
+				t.Errorf("Lex() trailing = %v, want %v", len(trailing), tt.trailing)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	s := "SELECT Id,\n       Account.Name  -- the account\n  FROM Contact c\n WHERE c.Account.Name LIKE 'a%' /* prefix */\n ORDER BY Account.Name DESC\n"
+
+	tree, err := cst.Parse(s)
+	if err != nil {
+		t.Errorf("Parse() error = %v", err)
+		return
+	}
+	if tree.String() != s {
+		t.Errorf("String() = %v, want %v", tree.String(), s)
+	}
+
+	// Rename the field reference in the where clause.
+	where := &tree.Query.Where[0].Value
+	tokens := tree.TokensOf(where)
+	if len(tokens) != 5 || tokens[4].Text != "Name" {
+		t.Errorf("TokensOf() = %v tokens", len(tokens))
+		return
+	}
+	tokens[4].Text = "Title"
+
+	want := "SELECT Id,\n       Account.Name  -- the account\n  FROM Contact c\n WHERE c.Account.Title LIKE 'a%' /* prefix */\n ORDER BY Account.Name DESC\n"
+	if tree.String() != want {
+		t.Errorf("String() = %v, want %v", tree.String(), want)
+	}
+
+	if tok := tree.TokenAt(len(s) - 5); tok == nil || tok.Text != "DESC" {
+		t.Errorf("TokenAt() = %v", tok)
+	} else if _, ok := tok.Node.(*types.SoqlOrderByInfo); !ok {
+		t.Errorf("TokenAt() node = %T", tok.Node)
+	}
+
+	tree, err = cst.Parse("SELECT Id FROM")
+	if err == nil || tree.Query != nil || tree.String() != "SELECT Id FROM" {
+		t.Errorf("Parse() = %v, %v", tree, err)
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want []cst.ClassifiedToken
+	}{{
+		name: "1",
+		s:    "SELECT a.Id, COUNT(Name) cnt -- x\nFROM Account a WHERE Data = :p GROUP BY a.Id ORDER BY cnt",
+		want: []cst.ClassifiedToken{
+			{Class: class.Keyword, Text: "SELECT", Offset: 0, EndOffset: 6},
+			{Class: class.Alias, Text: "a", Offset: 7, EndOffset: 8},
+			{Class: class.Punctuation, Text: ".", Offset: 8, EndOffset: 9},
+			{Class: class.Identifier, Text: "Id", Offset: 9, EndOffset: 11},
+			{Class: class.Punctuation, Text: ",", Offset: 11, EndOffset: 12},
+			{Class: class.FunctionName, Text: "COUNT", Offset: 13, EndOffset: 18},
+			{Class: class.Punctuation, Text: "(", Offset: 18, EndOffset: 19},
+			{Class: class.Identifier, Text: "Name", Offset: 19, EndOffset: 23},
+			{Class: class.Punctuation, Text: ")", Offset: 23, EndOffset: 24},
+			{Class: class.Alias, Text: "cnt", Offset: 25, EndOffset: 28},
+			{Class: class.Comment, Text: "-- x\n", Offset: 29, EndOffset: 34},
+			{Class: class.Keyword, Text: "FROM", Offset: 34, EndOffset: 38},
+			{Class: class.Identifier, Text: "Account", Offset: 39, EndOffset: 46},
+			{Class: class.Alias, Text: "a", Offset: 47, EndOffset: 48},
+			{Class: class.Keyword, Text: "WHERE", Offset: 49, EndOffset: 54},
+			{Class: class.Identifier, Text: "Data", Offset: 55, EndOffset: 59},
+			{Class: class.Operator, Text: "=", Offset: 60, EndOffset: 61},
+			{Class: class.ParameterizedValue, Text: ":p", Offset: 62, EndOffset: 64},
+			{Class: class.Keyword, Text: "GROUP", Offset: 65, EndOffset: 70},
+			{Class: class.Keyword, Text: "BY", Offset: 71, EndOffset: 73},
+			{Class: class.Alias, Text: "a", Offset: 74, EndOffset: 75},
+			{Class: class.Punctuation, Text: ".", Offset: 75, EndOffset: 76},
+			{Class: class.Identifier, Text: "Id", Offset: 76, EndOffset: 78},
+			{Class: class.Keyword, Text: "ORDER", Offset: 79, EndOffset: 84},
+			{Class: class.Keyword, Text: "BY", Offset: 85, EndOffset: 87},
+			{Class: class.Alias, Text: "cnt", Offset: 88, EndOffset: 91},
+		},
+	}, {
+		name: "literals",
+		s:    "x = 'a' OR x = 1 OR x = 1.5 OR x = USD5 OR x = 2023-01-02 OR x = 2023-01-02T00:00:00Z OR x = 10:00:00Z OR x = TODAY OR x = NULL OR x = TRUE",
+		want: []cst.ClassifiedToken{
+			{Class: class.String, Text: "'a'"}, {Class: class.Int, Text: "1"}, {Class: class.Float, Text: "1.5"},
+			{Class: class.Currency, Text: "USD5"}, {Class: class.Date, Text: "2023-01-02"},
+			{Class: class.DateTime, Text: "2023-01-02T00:00:00Z"}, {Class: class.Time, Text: "10:00:00Z"},
+			{Class: class.DateTimeLiteralName, Text: "TODAY"}, {Class: class.Null, Text: "NULL"}, {Class: class.Bool, Text: "TRUE"},
+		},
+	}, {
+		name: "invalid",
+		s:    "SELECT Id, FROM Account a WHERE a.Name = 'x",
+		want: []cst.ClassifiedToken{
+			{Class: class.Keyword, Text: "SELECT", Offset: 0, EndOffset: 6},
+			{Class: class.Identifier, Text: "Id", Offset: 7, EndOffset: 9},
+			{Class: class.Punctuation, Text: ",", Offset: 9, EndOffset: 10},
+			{Class: class.Keyword, Text: "FROM", Offset: 11, EndOffset: 15},
+			{Class: class.Identifier, Text: "Account", Offset: 16, EndOffset: 23},
+			{Class: class.Alias, Text: "a", Offset: 24, EndOffset: 25},
+			{Class: class.Keyword, Text: "WHERE", Offset: 26, EndOffset: 31},
+			{Class: class.Alias, Text: "a", Offset: 32, EndOffset: 33},
+			{Class: class.Punctuation, Text: ".", Offset: 33, EndOffset: 34},
+			{Class: class.Identifier, Text: "Name", Offset: 34, EndOffset: 38},
+			{Class: class.Operator, Text: "=", Offset: 39, EndOffset: 40},
+			{Class: class.Unknown, Text: "'x", Offset: 41, EndOffset: 43},
+		},
+	}, {
+		name: "sosl",
+		s:    "FIND {a} RETURNING Account(Id WHERE Name = 'a'), Contact",
+		want: []cst.ClassifiedToken{
+			{Class: class.Keyword, Text: "FIND", Offset: 0, EndOffset: 4},
+			{Class: class.String, Text: "{a}", Offset: 5, EndOffset: 8},
+			{Class: class.Keyword, Text: "RETURNING", Offset: 9, EndOffset: 18},
+			{Class: class.Identifier, Text: "Account", Offset: 19, EndOffset: 26},
+			{Class: class.Punctuation, Text: "(", Offset: 26, EndOffset: 27},
+			{Class: class.Identifier, Text: "Id", Offset: 27, EndOffset: 29},
+			{Class: class.Keyword, Text: "WHERE", Offset: 30, EndOffset: 35},
+			{Class: class.Identifier, Text: "Name", Offset: 36, EndOffset: 40},
+			{Class: class.Operator, Text: "=", Offset: 41, EndOffset: 42},
+			{Class: class.String, Text: "'a'", Offset: 43, EndOffset: 46},
+			{Class: class.Punctuation, Text: ")", Offset: 46, EndOffset: 47},
+			{Class: class.Punctuation, Text: ",", Offset: 47, EndOffset: 48},
+			{Class: class.Identifier, Text: "Contact", Offset: 49, EndOffset: 56},
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := cst.Tokenize(tt.s)
+			if tt.name == "literals" {
+				// Compare the right hand side values only.
+				z := make([]cst.ClassifiedToken, 0)
+				for i := 2; i < len(got); i += 4 {
+					z = append(z, cst.ClassifiedToken{Class: got[i].Class, Text: got[i].Text})
+				}
+				got = z
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Tokenize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    string
+		wantErr bool
+	}{{
+		name: "1",
+		s:    "select  a.Id,concat(Name, 'x') cnt , (select id from contacts c where c.Name = 'x')\n -- comment\n from account a /* b */ where a.Data > -1 and calendar_year(CreatedDate) in (2020, 2021) order by a.Id desc nulls last limit 10 offset 5\n",
+		want: "SELECT a.Id, concat(Name, 'x') cnt, (SELECT id FROM contacts c WHERE c.Name = 'x')\n" +
+			"-- comment\n" +
+			"FROM account a /* b */\n" +
+			"WHERE a.Data > -1 AND calendar_year(CreatedDate) IN (2020, 2021)\n" +
+			"ORDER BY a.Id DESC NULLS LAST\n" +
+			"LIMIT 10\n" +
+			"OFFSET 5\n",
+	}, {
+		name: "group by",
+		s:    "SELECT Name, COUNT(Id) FROM Lead with security_enforced group by rollup(Name) having count(Id) > 1",
+		want: "SELECT Name, COUNT(Id)\nFROM Lead\nWITH SECURITY_ENFORCED\nGROUP BY ROLLUP(Name)\nHAVING count(Id) > 1",
+	}, {
+		name: "sosl",
+		s:    "find {foo} returning Account(Id, Name where Name like 'a%' limit 5), Contact limit 10 update tracking",
+		want: "FIND {foo}\nRETURNING Account(Id, Name WHERE Name LIKE 'a%' LIMIT 5), Contact\nLIMIT 10\nUPDATE TRACKING",
+	}, {
+		name:    "error",
+		s:       "SELECT Id FROM",
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cst.Format(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Format() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Format() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
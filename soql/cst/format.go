@@ -0,0 +1,207 @@
+package cst
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/shellyln/go-open-soql-parser/soql/parser"
+)
+
+func keywordAt(tokens []*Token, i int) string {
+	if i < 0 || len(tokens) <= i || tokens[i].Kind != TokenKind_Keyword {
+		return ""
+	}
+	return strings.ToLower(tokens[i].Text)
+}
+
+// Returns true if the token at i starts the clause of the outermost query.
+func isClauseStart(tokens []*Token, i int, search bool) bool {
+	switch keywordAt(tokens, i) {
+	case "from", "where", "having", "offset", "for":
+		return !search
+	case "with", "limit":
+		return true
+	case "group", "order":
+		return keywordAt(tokens, i+1) == "by"
+	case "all":
+		return keywordAt(tokens, i+1) == "rows"
+	case "in":
+		return search && i > 0 && tokens[i-1].Kind == TokenKind_SearchQuery
+	case "returning":
+		return search
+	case "update":
+		return search && keywordAt(tokens, i-1) != "for"
+	}
+	return false
+}
+
+// Returns true if the token at i is the unary operator (e.g. -1).
+func isUnaryOperator(tokens []*Token, i int) bool {
+	if tokens[i].Text != "-" && tokens[i].Text != "+" {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+	prev := tokens[i-1]
+	switch prev.Kind {
+	case TokenKind_Operator:
+		return true
+	case TokenKind_Keyword:
+		switch strings.ToLower(prev.Text) {
+		case "null", "true", "false":
+			return false
+		}
+		return true
+	}
+	return prev.Text == "(" || prev.Text == ","
+}
+
+func needsSpace(tokens []*Token, i int) bool {
+	prev, tok := tokens[i-1], tokens[i]
+	switch prev.Text {
+	case "(", ".", "[":
+		return false
+	}
+	switch tok.Text {
+	case ")", ",", ".", "[", "]", ";":
+		return false
+	case "(":
+		if prev.Kind == TokenKind_Identifier {
+			// Function call
+			return false
+		}
+		switch keywordAt(tokens, i-1) {
+		case "rollup", "cube":
+			return false
+		}
+	}
+	return !isUnaryOperator(tokens, i-1)
+}
+
+func formatKeyword(s string) string {
+	switch s = strings.ToLower(s); s {
+	case "null", "true", "false":
+		return s
+	}
+	return strings.ToUpper(s)
+}
+
+// Format the source.
+// Keywords are upper-cased, whitespaces are normalized and the clauses of the outermost query start on the new lines.
+// Comments are kept. It returns an error if the source has errors.
+func Format(s string) (string, error) {
+	tree, err := Parse(s)
+	if err != nil {
+		return "", err
+	}
+	search := tree.Search != nil
+	tokens := tree.Tokens
+
+	var sb strings.Builder
+	lineStart, afterComment := true, false
+
+	writeComments := func(trivia []Trivia) {
+		newLine := false
+		for _, t := range trivia {
+			if t.Kind == TriviaKind_Whitespace {
+				newLine = strings.ContainsAny(t.Text, "\r\n")
+				continue
+			}
+			switch {
+			case lineStart:
+			case newLine:
+				// The comment on its own line
+				sb.WriteByte('\n')
+			default:
+				sb.WriteByte(' ')
+			}
+			newLine = false
+			sb.WriteString(strings.TrimRight(t.Text, "\r\n"))
+			if t.Kind == TriviaKind_LineComment {
+				sb.WriteByte('\n')
+				lineStart, afterComment = true, false
+			} else {
+				lineStart, afterComment = false, true
+			}
+		}
+	}
+
+	depth := 0
+	for i, tok := range tokens {
+		writeComments(tok.Leading)
+
+		switch {
+		case lineStart:
+		case depth == 0 && isClauseStart(tokens, i, search):
+			sb.WriteByte('\n')
+		case afterComment || needsSpace(tokens, i):
+			sb.WriteByte(' ')
+		}
+
+		if tok.Kind == TokenKind_Keyword {
+			sb.WriteString(formatKeyword(tok.Text))
+		} else {
+			sb.WriteString(tok.Text)
+		}
+		lineStart, afterComment = false, false
+
+		switch tok.Text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		}
+	}
+	writeComments(tree.Trailing)
+
+	z := strings.TrimRight(sb.String(), "\r\n")
+	if strings.HasSuffix(s, "\n") {
+		z += "\n"
+	}
+
+	if err := checkFormatted(s, z, search); err != nil {
+		return "", err
+	}
+	return z, nil
+}
+
+// Check that the formatted source is parsed to the same query as the original source.
+func checkFormatted(s, formatted string, search bool) error {
+	var want, got string
+	if search {
+		q1, err := parser.ParseSosl(s)
+		if err != nil {
+			return err
+		}
+		q2, err := parser.ParseSosl(formatted)
+		if err != nil {
+			return errors.New("The formatted query has errors: " + err.Error())
+		}
+		if want, err = parser.FormatSosl(q1); err != nil {
+			return err
+		}
+		if got, err = parser.FormatSosl(q2); err != nil {
+			return err
+		}
+	} else {
+		q1, err := parser.Parse(s)
+		if err != nil {
+			return err
+		}
+		q2, err := parser.Parse(formatted)
+		if err != nil {
+			return errors.New("The formatted query has errors: " + err.Error())
+		}
+		if want, err = parser.Format(q1); err != nil {
+			return err
+		}
+		if got, err = parser.Format(q2); err != nil {
+			return err
+		}
+	}
+	if want != got {
+		return errors.New("The formatted query is not equivalent to the source")
+	}
+	return nil
+}
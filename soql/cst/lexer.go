@@ -0,0 +1,337 @@
+package cst
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/shellyln/go-open-soql-parser/soql/parser/types"
+)
+
+type TokenKind int
+
+const (
+	TokenKind_Keyword     TokenKind = iota + 1 // Reserved word (e.g. SELECT, FROM, AND, NULLS)
+	TokenKind_Identifier                       // Field, object, alias and function names; Quoted names ("...") are also identifiers.
+	TokenKind_String                           // 'string'
+	TokenKind_Number                           // Integer, float and currency literals
+	TokenKind_DateTime                         // Date, datetime and time literals
+	TokenKind_DateLiteral                      // Date literal names (e.g. TODAY, LAST_N_DAYS:5)
+	TokenKind_Parameter                        // Bind parameters (e.g. :name, :acc.Id)
+	TokenKind_Hint                             // Hinting strings (`...`)
+	TokenKind_SearchQuery                      // SOSL search query ({...})
+	TokenKind_Operator                         // Comparison, arithmetic and concatenation operators
+	TokenKind_Punctuation                      // ( ) , . : [ ] ;
+	TokenKind_Unknown                          // Unknown character or unterminated literal
+)
+
+func (t TokenKind) String() string {
+	switch t {
+	case TokenKind_Keyword:
+		return "Keyword"
+	case TokenKind_Identifier:
+		return "Identifier"
+	case TokenKind_String:
+		return "String"
+	case TokenKind_Number:
+		return "Number"
+	case TokenKind_DateTime:
+		return "DateTime"
+	case TokenKind_DateLiteral:
+		return "DateLiteral"
+	case TokenKind_Parameter:
+		return "Parameter"
+	case TokenKind_Hint:
+		return "Hint"
+	case TokenKind_SearchQuery:
+		return "SearchQuery"
+	case TokenKind_Operator:
+		return "Operator"
+	case TokenKind_Punctuation:
+		return "Punctuation"
+	case TokenKind_Unknown:
+		return "Unknown"
+	default:
+		return "Undefined"
+	}
+}
+
+type TriviaKind int
+
+const (
+	TriviaKind_Whitespace   TriviaKind = iota + 1 // Whitespaces and line breaks
+	TriviaKind_LineComment                        // -- comment (including the line break)
+	TriviaKind_BlockComment                       // /* comment */
+)
+
+func (t TriviaKind) String() string {
+	switch t {
+	case TriviaKind_Whitespace:
+		return "Whitespace"
+	case TriviaKind_LineComment:
+		return "LineComment"
+	case TriviaKind_BlockComment:
+		return "BlockComment"
+	default:
+		return "Undefined"
+	}
+}
+
+// Whitespaces and comments between the tokens.
+type Trivia struct {
+	Kind   TriviaKind
+	Text   string
+	Offset int // Byte offset in the source
+}
+
+// Token of the concrete syntax tree.
+type Token struct {
+	Kind    TokenKind
+	Text    string   // Text as is in the source (keywords are not lower-cased); Replace it to rewrite the query.
+	Offset  int      // Byte offset in the source
+	Leading []Trivia // Whitespaces and comments before the token
+
+	// The innermost node built from the token; nil if the token is not a part of any node (e.g. keywords of the clauses).
+	// It is *types.SoqlFieldInfo, *types.SoqlObjectInfo, *types.SoqlCondition or *types.SoqlOrderByInfo.
+	Node interface{}
+}
+
+// End offset of the token in the source (exclusive).
+func (t *Token) End() int {
+	return t.Offset + len(t.Text)
+}
+
+var keywords = map[string]struct{}{
+	"select": {}, "from": {}, "where": {}, "and": {}, "or": {}, "not": {},
+	"like": {}, "in": {}, "includes": {}, "excludes": {},
+	"order": {}, "group": {}, "by": {}, "having": {}, "asc": {}, "desc": {}, "nulls": {}, "first": {}, "last": {},
+	"rollup": {}, "cube": {}, "offset": {}, "limit": {}, "all": {}, "rows": {},
+	"with": {}, "data": {}, "category": {}, "at": {}, "above": {}, "below": {}, "above_or_below": {},
+	"security_enforced": {}, "user_mode": {}, "system_mode": {},
+	"for": {}, "view": {}, "reference": {}, "update": {}, "tracking": {}, "viewstat": {},
+	"using": {}, "scope": {}, "typeof": {}, "when": {}, "then": {}, "else": {}, "end": {},
+	"null": {}, "true": {}, "false": {}, "find": {}, "returning": {},
+}
+
+var dateLiterals = map[string]struct{}{
+	"yesterday": {}, "today": {}, "tomorrow": {},
+	"last_week": {}, "this_week": {}, "next_week": {},
+	"last_month": {}, "this_month": {}, "next_month": {},
+	"last_90_days": {}, "next_90_days": {},
+	"this_quarter": {}, "last_quarter": {}, "next_quarter": {},
+	"this_year": {}, "last_year": {}, "next_year": {},
+	"this_fiscal_quarter": {}, "last_fiscal_quarter": {}, "next_fiscal_quarter": {},
+	"this_fiscal_year": {}, "last_fiscal_year": {}, "next_fiscal_year": {},
+}
+
+var (
+	dateTimeRe = regexp.MustCompile(`^[0-9]{4,}-[0-9]{2}-[0-9]{2}T[0-9]{2}:[0-9]{2}(:[0-9]{2}(\.[0-9]+)?)?(Z|[+-][0-9]{2}(:?[0-9]{2})?)`)
+	dateRe     = regexp.MustCompile(`^[0-9]{4,}-[0-9]{2}-[0-9]{2}`)
+	timeRe     = regexp.MustCompile(`^[0-9]{2}:[0-9]{2}(:[0-9]{2}(\.[0-9]+)?)?(Z|[+-][0-9]{2}(:?[0-9]{2})?)?`)
+	numberRe   = regexp.MustCompile(`^(0[bB][01]+|0[oO][0-7]+|0[xX][0-9A-Fa-f]+|([0-9]+(\.[0-9]*)?|\.[0-9]+)([eE][+-]?[0-9]+)?)`)
+	currencyRe = regexp.MustCompile(`^[A-Z]{3}[0-9]+(\.[0-9]+)?`)
+	dateNRe    = regexp.MustCompile(`^(?i)(next|last)_n_[a-z_]+:[0-9]+`)
+)
+
+var operators = []string{"!=", "<>", "<=", ">=", "||", "=", "<", ">", "+", "-", "*", "/"}
+
+func isSymbolChar(c byte) bool {
+	return 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '_' || c == '$'
+}
+
+func isSymbolStart(c byte) bool {
+	return 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || c == '_' || c == '$'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == '\v'
+}
+
+// Returns the end of the quoted text. s[i] is the opening quote.
+// If it is not terminated, ok is false and the end of the source is returned.
+func skipQuoted(s string, i int, quote byte, escape bool) (int, bool) {
+	for i++; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if escape {
+				i++
+			}
+		case quote:
+			return i + 1, true
+		}
+	}
+	return len(s), false
+}
+
+// Returns the end of the bind expression. s[i] is the next character of the colon.
+func skipBindExpression(s string, i int) int {
+	end := i
+	for end < len(s) && isSymbolChar(s[end]) {
+		end++
+	}
+	for end < len(s) {
+		switch s[end] {
+		case '.':
+			if end+1 < len(s) && isSymbolStart(s[end+1]) {
+				end++
+				for end < len(s) && isSymbolChar(s[end]) {
+					end++
+				}
+				continue
+			}
+		case '(':
+			if end+1 < len(s) && s[end+1] == ')' {
+				end += 2
+				continue
+			}
+		case '[':
+			if j := strings.IndexByte(s[end:], ']'); j > 0 {
+				end += j + 1
+				continue
+			}
+		}
+		break
+	}
+	return end
+}
+
+// Returns the trivia at s[i:], or false if s[i:] is not a trivia.
+func lexTrivia(s string, i int) (Trivia, bool) {
+	c := s[i]
+	switch {
+	case isSpace(c):
+		end := i
+		for end < len(s) && isSpace(s[end]) {
+			end++
+		}
+		return Trivia{Kind: TriviaKind_Whitespace, Text: s[i:end], Offset: i}, true
+	case strings.HasPrefix(s[i:], "--"):
+		end := len(s)
+		if j := strings.IndexAny(s[i:], "\r\n"); j >= 0 {
+			end = i + j + 1
+			if s[end-1] == '\r' && end < len(s) && s[end] == '\n' {
+				end++
+			}
+		}
+		return Trivia{Kind: TriviaKind_LineComment, Text: s[i:end], Offset: i}, true
+	case strings.HasPrefix(s[i:], "/*"):
+		end := len(s)
+		if j := strings.Index(s[i+2:], "*/"); j >= 0 {
+			end = i + 2 + j + 2
+		}
+		return Trivia{Kind: TriviaKind_BlockComment, Text: s[i:end], Offset: i}, true
+	}
+	return Trivia{}, false
+}
+
+// Returns the kind and the end of the token at s[i:].
+func lexToken(s string, i int, prev *Token) (TokenKind, int) {
+	c := s[i]
+	rest := s[i:]
+
+	switch {
+	case c == '\'':
+		if end, ok := skipQuoted(s, i, '\'', true); ok {
+			return TokenKind_String, end
+		}
+		return TokenKind_Unknown, len(s)
+	case c == '"':
+		if end, ok := skipQuoted(s, i, '"', true); ok {
+			return TokenKind_Identifier, end
+		}
+		return TokenKind_Unknown, len(s)
+	case c == '`':
+		if end, ok := skipQuoted(s, i, '`', false); ok {
+			return TokenKind_Hint, end
+		}
+		return TokenKind_Unknown, len(s)
+	case c == '{':
+		if end, ok := skipQuoted(s, i, '}', true); ok {
+			return TokenKind_SearchQuery, end
+		}
+		return TokenKind_Unknown, len(s)
+	case c == ':':
+		if i+1 < len(s) && isSymbolStart(s[i+1]) {
+			return TokenKind_Parameter, skipBindExpression(s, i+1)
+		}
+		return TokenKind_Punctuation, i + 1
+	case '0' <= c && c <= '9' || c == '.' && i+1 < len(s) && '0' <= s[i+1] && s[i+1] <= '9' &&
+		(prev == nil || prev.Kind != TokenKind_Identifier || prev.End() != i):
+
+		for _, re := range []*regexp.Regexp{dateTimeRe, dateRe, timeRe} {
+			if m := re.FindString(rest); m != "" {
+				return TokenKind_DateTime, i + len(m)
+			}
+		}
+		m := numberRe.FindString(rest)
+		return TokenKind_Number, i + len(m)
+	case isSymbolStart(c):
+		if m := currencyRe.FindString(rest); m != "" && types.IsCurrencyCode(m[:3]) &&
+			(i+len(m) == len(s) || !isSymbolChar(s[i+len(m)])) {
+			return TokenKind_Number, i + len(m)
+		}
+		if m := dateNRe.FindString(rest); m != "" {
+			return TokenKind_DateLiteral, i + len(m)
+		}
+		end := i
+		for end < len(s) && isSymbolChar(s[end]) {
+			end++
+		}
+		word := strings.ToLower(s[i:end])
+		if prev != nil && prev.Text == "." && prev.End() == i {
+			// Names just after the dot (e.g. Account.Limit) are not keywords.
+			// If there is a space (e.g. "SELECT Account. FROM"), the incomplete name is followed by a keyword.
+			return TokenKind_Identifier, end
+		}
+		if _, ok := keywords[word]; ok {
+			return TokenKind_Keyword, end
+		}
+		if _, ok := dateLiterals[word]; ok {
+			return TokenKind_DateLiteral, end
+		}
+		return TokenKind_Identifier, end
+	}
+
+	for _, op := range operators {
+		if strings.HasPrefix(rest, op) {
+			return TokenKind_Operator, i + len(op)
+		}
+	}
+	switch c {
+	case '(', ')', ',', '.', '[', ']', ';':
+		return TokenKind_Punctuation, i + 1
+	}
+	return TokenKind_Unknown, i + 1
+}
+
+// Split the source into the tokens losslessly.
+// It works on any input; The characters that are not the SOQL tokens are returned as TokenKind_Unknown.
+// The trivia after the last token is returned as trailing.
+func Lex(s string) (tokens []*Token, trailing []Trivia) {
+	tokens = make([]*Token, 0)
+	leading := make([]Trivia, 0)
+
+	for i := 0; i < len(s); {
+		if trivia, ok := lexTrivia(s, i); ok {
+			leading = append(leading, trivia)
+			i += len(trivia.Text)
+			continue
+		}
+
+		var prev *Token
+		if len(tokens) != 0 {
+			prev = tokens[len(tokens)-1]
+		}
+		kind, end := lexToken(s, i, prev)
+
+		tokens = append(tokens, &Token{
+			Kind:    kind,
+			Text:    s[i:end],
+			Offset:  i,
+			Leading: leading,
+		})
+		leading = make([]Trivia, 0)
+		i = end
+	}
+
+	return tokens, leading
+}
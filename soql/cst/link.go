@@ -0,0 +1,108 @@
+package cst
+
+import (
+	"strings"
+
+	. "github.com/shellyln/go-open-soql-parser/soql/parser/types"
+)
+
+type node struct {
+	span *SoqlSourceSpan
+	ptr  interface{}
+}
+
+func collectFieldNodes(nodes []node, fields []SoqlFieldInfo) []node {
+	for i := 0; i < len(fields); i++ {
+		f := &fields[i]
+		if f.NotSelected || f.Span == nil {
+			// The fields added by the normalization share the span with the original reference.
+			continue
+		}
+		nodes = append(nodes, node{span: f.Span, ptr: f})
+		nodes = collectFieldNodes(nodes, f.Parameters)
+		for j := 0; j < len(f.Branches); j++ {
+			nodes = collectFieldNodes(nodes, f.Branches[j].Fields)
+		}
+		if f.SubQuery != nil {
+			nodes = collectQueryNodes(nodes, f.SubQuery)
+		}
+	}
+	return nodes
+}
+
+func collectConditionNodes(nodes []node, cond []SoqlCondition) []node {
+	for i := 0; i < len(cond); i++ {
+		c := &cond[i]
+		if c.Opcode == SoqlConditionOpcode_FieldInfo {
+			// The operand precedes the condition that has the same span.
+			n := len(nodes)
+			nodes = collectFieldNodes(nodes, []SoqlFieldInfo{c.Value})
+			if len(nodes) > n {
+				nodes[n].ptr = &c.Value
+			}
+		}
+		if c.Span != nil {
+			nodes = append(nodes, node{span: c.Span, ptr: c})
+		}
+	}
+	return nodes
+}
+
+func collectQueryNodes(nodes []node, q *SoqlQuery) []node {
+	nodes = collectFieldNodes(nodes, q.Fields)
+	for i := 0; i < len(q.From); i++ {
+		if q.From[i].Span != nil {
+			nodes = append(nodes, node{span: q.From[i].Span, ptr: &q.From[i]})
+		}
+	}
+	nodes = collectConditionNodes(nodes, q.Where)
+	nodes = collectFieldNodes(nodes, q.GroupBy)
+	nodes = collectConditionNodes(nodes, q.Having)
+	for i := 0; i < len(q.OrderBy); i++ {
+		o := &q.OrderBy[i]
+		if o.Field.Span != nil {
+			nodes = append(nodes, node{span: o.Field.Span, ptr: &o.Field})
+		}
+		if o.Span != nil {
+			nodes = append(nodes, node{span: o.Span, ptr: o})
+		}
+	}
+	return nodes
+}
+
+// Link each token to the innermost node whose span contains the token.
+// If the spans are the same, the node that is collected first is preferred.
+func linkTokens(tokens []*Token, nodes []node) {
+	for _, tok := range tokens {
+		var found *node
+		for i := 0; i < len(nodes); i++ {
+			n := &nodes[i]
+			if n.span.Offset > tok.Offset || tok.End() > n.span.EndOffset {
+				continue
+			}
+			if found == nil || n.span.EndOffset-n.span.Offset < found.span.EndOffset-found.span.Offset {
+				found = n
+			}
+		}
+		if found == nil {
+			continue
+		}
+		tok.Node = found.ptr
+
+		if tok.Kind == TokenKind_Keyword && isNameToken(tok, found.ptr) {
+			// Keywords that are used as the names (e.g. SELECT Data FROM Foo).
+			tok.Kind = TokenKind_Identifier
+		}
+	}
+}
+
+func isNameToken(tok *Token, ptr interface{}) bool {
+	switch v := ptr.(type) {
+	case *SoqlFieldInfo:
+		return v.Type == SoqlFieldInfo_Field
+	case *SoqlObjectInfo:
+		s := strings.ToLower(tok.Text)
+		return s != "using" && s != "scope"
+	}
+	return false
+}
@@ -0,0 +1,160 @@
+package cst
+
+import (
+	"strings"
+
+	"github.com/shellyln/go-open-soql-parser/soql/parser/core/class"
+)
+
+// Classified token of the token stream.
+type ClassifiedToken struct {
+	Class     string `json:"class"`     // Token class; One of the constants of the class package (e.g. class.Keyword, class.String)
+	Text      string `json:"text"`      // Text as is in the source
+	Offset    int    `json:"offset"`    // Start byte offset in the source
+	EndOffset int    `json:"endOffset"` // End byte offset in the source (exclusive)
+}
+
+// Split the source into the classified tokens (e.g. for the syntax highlighting).
+// Comments are returned as the tokens of class.Comment, and whitespaces are not returned.
+// It also works on the invalid or incomplete source; The names are classified by the surrounding tokens,
+// and if the source is parsed successfully, keywords that are used as the names are classified as class.Identifier.
+func Tokenize(s string) []ClassifiedToken {
+	tree, _ := Parse(s)
+	classes := classifyTokens(tree.Tokens)
+
+	z := make([]ClassifiedToken, 0, len(tree.Tokens))
+	for i, tok := range tree.Tokens {
+		z = appendComments(z, tok.Leading)
+		z = append(z, ClassifiedToken{
+			Class:     classes[i],
+			Text:      tok.Text,
+			Offset:    tok.Offset,
+			EndOffset: tok.End(),
+		})
+	}
+	z = appendComments(z, tree.Trailing)
+
+	return z
+}
+
+func appendComments(z []ClassifiedToken, trivia []Trivia) []ClassifiedToken {
+	for _, t := range trivia {
+		if t.Kind == TriviaKind_Whitespace {
+			continue
+		}
+		z = append(z, ClassifiedToken{
+			Class:     class.Comment,
+			Text:      t.Text,
+			Offset:    t.Offset,
+			EndOffset: t.Offset + len(t.Text),
+		})
+	}
+	return z
+}
+
+func tokenTextAt(tokens []*Token, i int) string {
+	if i < 0 || len(tokens) <= i {
+		return ""
+	}
+	return tokens[i].Text
+}
+
+func classifyTokens(tokens []*Token) []string {
+	classes := make([]string, len(tokens))
+	aliases := make(map[string]struct{})
+	returning := false
+	depth := 0
+
+	for i, tok := range tokens {
+		prev, next := tokenTextAt(tokens, i-1), tokenTextAt(tokens, i+1)
+
+		switch tok.Kind {
+		case TokenKind_Keyword:
+			switch strings.ToLower(tok.Text) {
+			case "true", "false":
+				classes[i] = class.Bool
+			case "null":
+				classes[i] = class.Null
+			case "returning":
+				returning = true
+				depth = 0
+				classes[i] = class.Keyword
+			default:
+				classes[i] = class.Keyword
+			}
+		case TokenKind_Identifier:
+			switch {
+			case next == "(" && !(returning && depth == 0):
+				// Object names of the SOSL RETURNING clause (e.g. Account(Id)) are not functions.
+				classes[i] = class.FunctionName
+			case i > 0 && prev != "." && next != "." &&
+				(tokens[i-1].Kind == TokenKind_Identifier && classes[i-1] != class.FunctionName || prev == ")"):
+				// A name that follows the field, the function call or the object (e.g. COUNT(Id) cnt, Account a).
+				classes[i] = class.Alias
+				aliases[strings.ToLower(tok.Text)] = struct{}{}
+			default:
+				classes[i] = class.Identifier
+			}
+		case TokenKind_String, TokenKind_SearchQuery:
+			classes[i] = class.String
+		case TokenKind_Number:
+			classes[i] = numberClass(tok.Text)
+		case TokenKind_DateTime:
+			classes[i] = dateTimeClass(tok.Text)
+		case TokenKind_DateLiteral:
+			classes[i] = class.DateTimeLiteralName
+		case TokenKind_Parameter:
+			classes[i] = class.ParameterizedValue
+		case TokenKind_Hint:
+			classes[i] = class.Hints
+		case TokenKind_Operator:
+			classes[i] = class.Operator
+		case TokenKind_Punctuation:
+			switch tok.Text {
+			case "(":
+				depth++
+			case ")":
+				depth--
+			}
+			classes[i] = class.Punctuation
+		default:
+			classes[i] = class.Unknown
+		}
+	}
+
+	// References to the aliases (e.g. a.Name, ORDER BY cnt)
+	for i, tok := range tokens {
+		if classes[i] != class.Identifier || tokenTextAt(tokens, i-1) == "." {
+			continue
+		}
+		if _, ok := aliases[strings.ToLower(tok.Text)]; ok {
+			classes[i] = class.Alias
+		}
+	}
+
+	return classes
+}
+
+func numberClass(s string) string {
+	switch {
+	case isSymbolStart(s[0]):
+		return class.Currency
+	case len(s) > 1 && s[0] == '0' && strings.ContainsAny(s[1:2], "bBoOxX"):
+		return class.Int
+	case strings.ContainsAny(s, ".eE"):
+		return class.Float
+	default:
+		return class.Int
+	}
+}
+
+func dateTimeClass(s string) string {
+	switch {
+	case strings.Contains(s, "T"):
+		return class.DateTime
+	case strings.Contains(s, ":"):
+		return class.Time
+	default:
+		return class.Date
+	}
+}
@@ -7,6 +7,8 @@ const (
 	Blob         = "soql:BlobValue"
 	Int          = "soql:IntValue"
 	Float        = "soql:FloatValue"
+	Decimal      = "soql:DecimalValue"
+	Currency     = "soql:CurrencyValue"
 	Date         = "soql:DateValue"
 	DateTime     = "soql:DateTimeValue"
 	Time         = "soql:TimeValue"
@@ -19,4 +21,17 @@ const (
 	DateTimeLiteralName     = "soql:DateTimeLiteralName"
 	SelectFieldFunctionCall = "soql:SelectFieldFunctionCall"
 	SubQuery                = "soql:SubQuery"
+	Hints                   = "soql:Hints"
+)
+
+// Token classes that are only used by the token stream (cst.Tokenize).
+const (
+	Keyword      = "soql:Keyword"
+	Identifier   = "soql:Identifier"
+	Alias        = "soql:Alias"
+	FunctionName = "soql:FunctionName"
+	Comment      = "soql:Comment"
+	Operator     = "soql:Operator"
+	Punctuation  = "soql:Punctuation"
+	Unknown      = "soql:Unknown"
 )
@@ -1,6 +1,9 @@
 package core
 
 import (
+	"errors"
+	"math/big"
+	"strconv"
 	"strings"
 	"time"
 
@@ -59,6 +62,10 @@ func reservedKeywords() ParserFn {
 			FlatGroup(SeqI("having"), WordBoundary()),
 			FlatGroup(SeqI("offset"), WordBoundary()),
 			FlatGroup(SeqI("limit"), WordBoundary()),
+			FlatGroup(SeqI("with"), WordBoundary()),
+			FlatGroup(SeqI("for"), WordBoundary()),
+			FlatGroup(SeqI("using"), WordBoundary()),
+			FlatGroup(SeqI("all"), sp1(), SeqI("rows"), WordBoundary()),
 		),
 		Concat,
 	)
@@ -71,6 +78,23 @@ func notAheadReservedKeywords() ParserFn {
 	)
 }
 
+// Binary logical operators of the conditional expressions
+func logicalOperators() ParserFn {
+	return FlatGroup(
+		First(
+			SeqI("and"),
+			SeqI("or"),
+		),
+		WordBoundary(),
+	)
+}
+
+func notAheadLogicalOperators() ParserFn {
+	return LookAheadN(
+		logicalOperators(),
+	)
+}
+
 func wordBoundary() ParserFn {
 	return WordBoundary()
 }
@@ -141,6 +165,33 @@ func decimalPositiveIntegerValue() ParserFn {
 	)
 }
 
+// Parse the number as Decimal or float64 by the parser option.
+func transFloatNumber(ctx ParserContext, asts AstSlice) (AstSlice, error) {
+	if !getParserOptions(ctx).DecimalLiteral {
+		asts, err := ParseFloat(ctx, asts)
+		if err != nil {
+			return nil, err
+		}
+		return ChangeClassName(class.Float)(ctx, asts)
+	}
+
+	asts, err := Concat(ctx, asts)
+	if err != nil {
+		return nil, err
+	}
+
+	s := strings.TrimPrefix(asts[0].Value.(string), "+")
+	if _, ok := new(big.Rat).SetString(s); !ok {
+		return nil, errors.New("Bad decimal number format: " + s)
+	}
+
+	return AstSlice{{
+		ClassName: class.Decimal,
+		Type:      AstType_Any,
+		Value:     SoqlDecimal(s),
+	}}, nil
+}
+
 func numberValue() ParserFn {
 	return First(
 		FlatGroup(
@@ -160,11 +211,9 @@ func numberValue() ParserFn {
 					ParseIntRadix(16),
 					ChangeClassName(class.Int),
 				),
-				// TODO: Big decimal number
 				Trans(
 					extra.FloatNumberStr(),
-					ParseFloat,
-					ChangeClassName(class.Float),
+					transFloatNumber,
 				),
 				Trans(
 					extra.IntegerNumberStr(),
@@ -180,6 +229,37 @@ func numberValue() ParserFn {
 	)
 }
 
+// Currency literal (e.g. USD5000, EUR100.50)
+// The code should be the ISO 4217 code; Otherwise (e.g. ABC123) it is the field name.
+func currencyValue() ParserFn {
+	return Trans(
+		FlatGroup(
+			CharClass(IsoCurrencyCodes...),
+			Trans(
+				FlatGroup(
+					OneOrMoreTimes(CharRange(RuneRange{Start: '0', End: '9'})),
+					ZeroOrOnce(
+						Seq("."),
+						OneOrMoreTimes(CharRange(RuneRange{Start: '0', End: '9'})),
+					),
+				),
+				Concat,
+			),
+			wordBoundary(),
+		),
+		func(ctx ParserContext, asts AstSlice) (AstSlice, error) {
+			return AstSlice{{
+				ClassName: class.Currency,
+				Type:      AstType_Any,
+				Value: SoqlCurrency{
+					Code:  asts[0].Value.(string),
+					Value: SoqlDecimal(asts[1].Value.(string)),
+				},
+			}}, nil
+		},
+	)
+}
+
 func stringLiteralInner(cc string, multiline bool) ParserFn {
 	return FlatGroup(
 		erase(Seq(cc)),
@@ -281,46 +361,82 @@ func dateValue() ParserFn {
 	)
 }
 
+func digits(n int) ParserFn {
+	return Repeat(Times{Min: n, Max: n}, CharRange(RuneRange{Start: '0', End: '9'}))
+}
+
+func timeOfDayStr() ParserFn {
+	return FlatGroup(
+		digits(2),
+		Seq(":"),
+		digits(2),
+		ZeroOrOnce(
+			Seq(":"),
+			digits(2),
+			ZeroOrOnce(
+				Seq("."),
+				Repeat(Times{Min: 1, Max: 9}, // 3: milli, 6: micro, 9: nano
+					CharRange(RuneRange{Start: '0', End: '9'}),
+				),
+			),
+		),
+	)
+}
+
+func timeOffsetStr() ParserFn {
+	return First(
+		Seq("Z"),
+		FlatGroup(
+			CharClass("+", "-"),
+			digits(2),
+			Seq(":"),
+			digits(2),
+		),
+	)
+}
+
+func transDateTimeValue(className string) TransformerFn {
+	return func(ctx ParserContext, asts AstSlice) (AstSlice, error) {
+		v, err := ParseSoqlDateTimeValue(asts[0].Value.(string))
+		if err != nil {
+			return nil, err
+		}
+		return AstSlice{{
+			ClassName: className,
+			Type:      AstType_Any,
+			Value:     v,
+		}}, nil
+	}
+}
+
 func dateTimeValue() ParserFn {
 	return Trans(
 		FlatGroup(
-			extra.DateTimeStr(),
+			ZeroOrOnce(CharClass("+", "-")),
+			Repeat(Times{Min: 4, Max: -1}, CharRange(RuneRange{Start: '0', End: '9'})),
+			Seq("-"),
+			digits(2),
+			Seq("-"),
+			digits(2),
+			Seq("T"),
+			timeOfDayStr(),
+			timeOffsetStr(),
 			wordBoundary(),
 		),
-		func(ctx ParserContext, asts AstSlice) (AstSlice, error) {
-			// TODO: BUG: Cannot parse years with negative values or years greater than or equal to 10000.
-			value := asts[len(asts)-1].Value.(string)
-			t, err := time.Parse("2006-01-02T15:04:05.000000000-07:00", value)
-			if err != nil {
-				return nil, err
-			}
-			return AstSlice{{
-				ClassName: class.DateTime,
-				Type:      AstType_Any,
-				Value:     t.UTC(),
-			}}, nil
-		},
+		Concat,
+		transDateTimeValue(class.DateTime),
 	)
 }
 
 func timeValue() ParserFn {
 	return Trans(
 		FlatGroup(
-			extra.TimeStr(),
+			timeOfDayStr(),
+			ZeroOrOnce(timeOffsetStr()),
 			wordBoundary(),
 		),
-		func(ctx ParserContext, asts AstSlice) (AstSlice, error) {
-			value := "1970-01-01T" + asts[len(asts)-1].Value.(string) + "+00:00"
-			t, err := time.Parse("2006-01-02T15:04:05.000000000-07:00", value)
-			if err != nil {
-				return nil, err
-			}
-			return AstSlice{{
-				ClassName: class.Time,
-				Type:      AstType_Any,
-				Value:     t.UTC(),
-			}}, nil
-		},
+		Concat,
+		transDateTimeValue(class.Time),
 	)
 }
 
@@ -332,7 +448,8 @@ func symbolStringValue() ParserFn {
 	)
 }
 
-// TODO: hinting string value: `...`
+// Hinting string value: `...`
+// The content is the Go struct tag like `name:"value"` pairs separated by spaces.
 // e.g. SELECT (
 //          SELECT
 //              id          xid    `type:"id"   description:"..."`
@@ -370,6 +487,84 @@ func symbolStringValue() ParserFn {
 //                  description:"..."`
 //      ) FROM account acc `uri:"account.csv"`
 
+func hintStringValue() ParserFn {
+	return Trans(
+		FlatGroup(
+			erase(Seq("`")),
+			ZeroOrMoreTimes(CharClassN("`")),
+			erase(Seq("`")),
+		),
+		Concat,
+		func(ctx ParserContext, asts AstSlice) (AstSlice, error) {
+			hints, err := parseHintString(asts[0].Value.(string))
+			if err != nil {
+				return nil, err
+			}
+			return AstSlice{{
+				ClassName: class.Hints,
+				Type:      AstType_Any,
+				Value:     hints,
+			}}, nil
+		},
+	)
+}
+
+func parseHintString(s string) ([]SoqlQueryHint, error) {
+	hints := make([]SoqlQueryHint, 0)
+
+	for {
+		s = strings.TrimLeft(s, " \t\r\n")
+		if s == "" {
+			break
+		}
+
+		i := 0
+		for i < len(s) && s[i] > ' ' && s[i] != ':' && s[i] != '"' && s[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(s) || s[i] != ':' || s[i+1] != '"' {
+			return nil, errors.New("Invalid hinting string: " + s)
+		}
+		name := s[:i]
+		s = s[i+1:]
+
+		i = 1
+		for i < len(s) && s[i] != '"' {
+			if s[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(s) {
+			return nil, errors.New("Invalid hinting string: " + name)
+		}
+
+		value, err := strconv.Unquote(s[:i+1])
+		if err != nil {
+			return nil, errors.New("Invalid hinting string: " + name)
+		}
+		s = s[i+1:]
+
+		hints = append(hints, SoqlQueryHint{
+			Name:  name,
+			Value: value,
+		})
+	}
+
+	return hints, nil
+}
+
+func hintString() ParserFn {
+	return First(
+		FlatGroup(
+			sp0(),
+			hintStringValue(),
+			sp0(),
+		),
+		Zero(Ast{Value: []SoqlQueryHint(nil)}),
+	)
+}
+
 func symbolName() ParserFn {
 	return Trans(
 		FlatGroup(
@@ -428,12 +623,57 @@ func complexSymbolName() ParserFn {
 	)
 }
 
+// Apex bind expression: dotted path, method call without arguments and index access.
+// (e.g. acc.Id , ids , someMap.keySet() , list[0] , list[i].Name)
+// The whitespaces and comments are removed from the expression text.
+func bindExpression() ParserFn {
+	return Trans(
+		FlatGroup(
+			symbolName(),
+			ZeroOrMoreTimes(
+				sp0(),
+				First(
+					FlatGroup(
+						Seq("."),
+						sp0(),
+						symbolName(),
+					),
+					FlatGroup(
+						Seq("("),
+						sp0(),
+						Seq(")"),
+					),
+					FlatGroup(
+						Seq("["),
+						sp0(),
+						First(
+							OneOrMoreTimes(Number()),
+							FlatGroup(
+								symbolName(),
+								ZeroOrMoreTimes(
+									sp0(),
+									Seq("."),
+									sp0(),
+									symbolName(),
+								),
+							),
+						),
+						sp0(),
+						Seq("]"),
+					),
+				),
+			),
+		),
+		Concat,
+	)
+}
+
 func parameterizedValue() ParserFn {
 	return Trans(
 		FlatGroup(
 			erase(Seq(":")),
 			sp0(),
-			symbolName(),
+			bindExpression(),
 		),
 		ChangeClassName(class.ParameterizedValue),
 	)
@@ -503,6 +743,7 @@ func literalValue() ParserFn {
 			dateValue(),
 			timeValue(),
 			numberValue(),
+			currencyValue(),
 			stringValue(),
 			trueValue(),
 			falseValue(),
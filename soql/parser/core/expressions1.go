@@ -19,6 +19,10 @@ func fieldInfoTypeFromClassName(className string) SoqlFieldInfoType {
 		ty = SoqlFieldInfo_Literal_Int
 	case class.Float:
 		ty = SoqlFieldInfo_Literal_Float
+	case class.Decimal:
+		ty = SoqlFieldInfo_Literal_Decimal
+	case class.Currency:
+		ty = SoqlFieldInfo_Literal_Currency
 	case class.Bool:
 		ty = SoqlFieldInfo_Literal_Bool
 	case class.String:
@@ -53,20 +57,20 @@ func selectFieldFunctionCall() ParserFn {
 				FlatGroup(
 					sp0(),
 					ZeroOrOnce(
-						First(
+						spanned(First(
 							Indirect(selectFieldFunctionCall),
 							complexSymbolName(),
 							literalValue(),
-						),
+						)),
 						ZeroOrMoreTimes(
 							sp0(),
 							erase(CharClass(",")),
 							sp0(),
-							First(
+							spanned(First(
 								Indirect(selectFieldFunctionCall),
 								complexSymbolName(),
 								literalValue(),
-							),
+							)),
 						),
 						sp0(),
 					),
@@ -87,6 +91,7 @@ func selectFieldFunctionCall() ParserFn {
 					z[i-1] = SoqlFieldInfo{
 						Type: SoqlFieldInfo_Field,
 						Name: asts[i].Value.([]string),
+						Span: spanOf(asts[i]),
 					}
 				default:
 					{
@@ -101,12 +106,14 @@ func selectFieldFunctionCall() ParserFn {
 								Type:      ty,
 								ClassName: asts[i].ClassName,
 								Name:      []string{asts[i].Value.(string)},
+								Span:      spanOf(asts[i]),
 							}
 						default:
 							z[i-1] = SoqlFieldInfo{
 								Type:      ty,
 								ClassName: asts[i].ClassName,
 								Value:     asts[i].Value,
+								Span:      spanOf(asts[i]),
 							}
 						}
 					}
@@ -199,29 +206,148 @@ func transComplexSelectFieldName(ctx ParserContext, asts AstSlice) (AstSlice, er
 	}}, nil
 }
 
-func complexSelectFieldName() ParserFn {
+func typeOfFieldList() ParserFn {
 	return Trans(
 		FlatGroup(
-			notAheadReservedKeywords(),
-			First(
-				selectFieldFunctionCall(), // SoqlFieldInfo
-				complexSymbolName(),       // []string
-				subQuery(),                // SoqlQuery
+			spanned(complexSymbolName()),
+			sp0(),
+			ZeroOrMoreTimes(
+				erase(CharClass(",")),
+				sp0(),
+				spanned(complexSymbolName()),
+				sp0(),
 			),
+		),
+		func(ctx ParserContext, asts AstSlice) (AstSlice, error) {
+			astsLen := len(asts)
+			z := make([]SoqlFieldInfo, astsLen, astsLen)
+			for i := 0; i < astsLen; i++ {
+				z[i] = SoqlFieldInfo{
+					Type: SoqlFieldInfo_Field,
+					Name: asts[i].Value.([]string),
+					Span: spanOf(asts[i]),
+				}
+			}
+			return AstSlice{{
+				ClassName: "soql:TypeOfFieldList",
+				Type:      AstType_Any,
+				Value:     z,
+			}}, nil
+		},
+	)
+}
+
+func typeOfExpression() ParserFn {
+	return Trans(
+		FlatGroup(
+			erase(SeqI("typeof")),
+			sp1(),
+			complexSymbolName(),
+			LookAhead(SeqI("when"), wordBoundary()),
 			First(
-				// Alias name
 				FlatGroup(
-					notAheadReservedKeywords(),
-					symbolName(),
+					OneOrMoreTimes(
+						Trans(
+							FlatGroup(
+								erase(SeqI("when")),
+								sp1(),
+								symbolName(),
+								sp1(),
+								erase(SeqI("then")),
+								sp1(),
+								typeOfFieldList(),
+							),
+							func(ctx ParserContext, asts AstSlice) (AstSlice, error) {
+								return AstSlice{{
+									ClassName: "soql:TypeOfBranch",
+									Type:      AstType_Any,
+									Value: SoqlTypeOfBranch{
+										ObjectType: asts[0].Value.(string),
+										Fields:     asts[1].Value.([]SoqlFieldInfo),
+									},
+								}}, nil
+							},
+						),
+					),
+					ZeroOrOnce(
+						Trans(
+							FlatGroup(
+								erase(SeqI("else")),
+								sp1(),
+								typeOfFieldList(),
+							),
+							func(ctx ParserContext, asts AstSlice) (AstSlice, error) {
+								return AstSlice{{
+									ClassName: "soql:TypeOfBranch",
+									Type:      AstType_Any,
+									Value: SoqlTypeOfBranch{
+										Fields: asts[0].Value.([]SoqlFieldInfo),
+									},
+								}}, nil
+							},
+						),
+					),
+					erase(SeqI("end")),
+					wordBoundary(),
+					sp0(),
 				),
-				Zero(Ast{Value: ""}),
+				Error("Unexpected token aheads near by the 'typeof' expression"),
 			),
-			// TODO: hinting string
 		),
-		transComplexSelectFieldName,
+		func(ctx ParserContext, asts AstSlice) (AstSlice, error) {
+			astsLen := len(asts)
+			branches := make([]SoqlTypeOfBranch, astsLen-1, astsLen-1)
+			for i := 1; i < astsLen; i++ {
+				branches[i-1] = asts[i].Value.(SoqlTypeOfBranch)
+			}
+			return AstSlice{{
+				ClassName: "soql:TypeOf",
+				Type:      AstType_Any,
+				Value: SoqlFieldInfo{
+					Type:     SoqlFieldInfo_TypeOf,
+					Name:     asts[0].Value.([]string),
+					Branches: branches,
+				},
+			}}, nil
+		},
 	)
 }
 
+func complexSelectFieldName() ParserFn {
+	return spanned(First(
+		typeOfExpression(), // SoqlFieldInfo
+		Trans(
+			FlatGroup(
+				notAheadReservedKeywords(),
+				First(
+					selectFieldFunctionCall(), // SoqlFieldInfo
+					complexSymbolName(),       // []string
+					subQuery(),                // SoqlQuery
+				),
+				First(
+					// Alias name
+					FlatGroup(
+						notAheadReservedKeywords(),
+						symbolName(),
+					),
+					Zero(Ast{Value: ""}),
+				),
+				hintString(),
+			),
+			func(ctx ParserContext, asts AstSlice) (AstSlice, error) {
+				z, err := transComplexSelectFieldName(ctx, asts[:2])
+				if err != nil {
+					return nil, err
+				}
+				field := z[0].Value.(SoqlFieldInfo)
+				field.Hints = asts[2].Value.([]SoqlQueryHint)
+				z[0].Value = field
+				return z, nil
+			},
+		),
+	))
+}
+
 func selectFieldList() ParserFn {
 	return Trans(
 		FlatGroup(
@@ -259,27 +385,66 @@ func selectFieldList() ParserFn {
 	)
 }
 
+func usingScopeClause() ParserFn {
+	return FlatGroup(
+		erase(SeqI("using")),
+		sp1(),
+		First(
+			FlatGroup(
+				erase(SeqI("scope")),
+				sp1(),
+				notAheadReservedKeywords(),
+				symbolName(),
+				sp0(),
+			),
+			Error("Unexpected token aheads near by the 'using scope' clause"),
+		),
+	)
+}
+
+// Object of the from clause. The span is set to the resulting SoqlObjectInfo.
+func fromObject() ParserFn {
+	return spanned(Trans(
+		FlatGroup(
+			notAheadReservedKeywords(),
+			complexSymbolName(),
+			First(
+				// Alias name
+				FlatGroup(
+					notAheadReservedKeywords(),
+					symbolName(),
+					sp0(),
+				),
+				Zero(Ast{Value: ""}),
+			),
+			First(
+				usingScopeClause(),
+				Zero(Ast{Value: ""}),
+			),
+			hintString(),
+		),
+		func(ctx ParserContext, asts AstSlice) (AstSlice, error) {
+			return AstSlice{{
+				ClassName: "soql:Object",
+				Type:      AstType_Any,
+				Value: SoqlObjectInfo{
+					Name:       asts[0].Value.([]string),
+					AliasName:  asts[1].Value.(string),
+					UsingScope: asts[2].Value.(string),
+					Hints:      asts[3].Value.([]SoqlQueryHint),
+				},
+			}}, nil
+		},
+	))
+}
+
 func fromClause() ParserFn {
 	return Trans(
 		FlatGroup(
 			erase(SeqI("from")),
 			sp1(),
 			First(
-				FlatGroup(
-					notAheadReservedKeywords(),
-					complexSymbolName(),
-					First(
-						// Alias name
-						FlatGroup(
-							notAheadReservedKeywords(),
-							symbolName(),
-							sp0(),
-						),
-						Zero(Ast{Value: ""}),
-					),
-					// TODO: `USING SCOPE` clause
-					// TODO: hinting string
-				),
+				fromObject(),
 				Error("Unexpected token aheads near by the 'from' clause"),
 			),
 			ZeroOrMoreTimes(
@@ -287,30 +452,17 @@ func fromClause() ParserFn {
 				First(
 					FlatGroup(
 						sp0(),
-						notAheadReservedKeywords(),
-						complexSymbolName(),
-						First(
-							// Alias name
-							FlatGroup(
-								notAheadReservedKeywords(),
-								symbolName(),
-								sp0(),
-							),
-							Zero(Ast{Value: ""}),
-						),
+						fromObject(),
 					),
 					Error("Unexpected token aheads near by the 'from' clause"),
 				),
 			),
 		),
 		func(ctx ParserContext, asts AstSlice) (AstSlice, error) {
-			astsLen := len(asts) / 2
+			astsLen := len(asts)
 			z := make([]SoqlObjectInfo, astsLen, astsLen)
 			for i := 0; i < astsLen; i++ {
-				z[i] = SoqlObjectInfo{
-					Name:      asts[i*2].Value.([]string),
-					AliasName: asts[i*2+1].Value.(string),
-				}
+				z[i] = asts[i].Value.(SoqlObjectInfo)
 			}
 			return AstSlice{{
 				ClassName: "soql:From",
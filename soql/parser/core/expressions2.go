@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/shellyln/go-open-soql-parser/soql/parser/core/class"
 	. "github.com/shellyln/go-open-soql-parser/soql/parser/types"
 	. "github.com/shellyln/takenoco/base"
 	. "github.com/shellyln/takenoco/string"
@@ -30,6 +31,7 @@ func conditionalOperator() ParserFn {
 						),
 						wordBoundary(),
 					),
+					Concat,
 				),
 				Trans(
 					FlatGroup(
@@ -67,16 +69,25 @@ func conditionalOperator() ParserFn {
 }
 
 func transWhereFieldExpression(ctx ParserContext, asts AstSlice) (AstSlice, error) {
+	// The literals are allowed only in the expression (e.g. `Amount + 1 > 10`), not as the bare left operand.
+	switch asts[0].Value.(SoqlFieldInfo).Type {
+	case SoqlFieldInfo_Field, SoqlFieldInfo_Function:
+	default:
+		return nil, errorAt(asts[0], "The left-hand side of the conditional expression must be a field or an expression")
+	}
+
 	cond := make([]SoqlCondition, 3, 3)
 
 	cond[0] = SoqlCondition{
 		Opcode: SoqlConditionOpcode_FieldInfo,
 		Value:  asts[0].Value.(SoqlFieldInfo),
 	}
+	cond[0].Span = cond[0].Value.Span
 	cond[1] = SoqlCondition{
 		Opcode: SoqlConditionOpcode_FieldInfo,
 		Value:  asts[2].Value.(SoqlFieldInfo),
 	}
+	cond[1].Span = cond[1].Value.Span
 
 	opcode := SoqlConditionOpcode_Noop
 	switch asts[1].Value.(string) {
@@ -115,6 +126,7 @@ func transWhereFieldExpression(ctx ParserContext, asts AstSlice) (AstSlice, erro
 	}
 	cond[2] = SoqlCondition{
 		Opcode: opcode,
+		Span:   joinSpans(cond[0].Span, cond[1].Span),
 	}
 
 	return AstSlice{{
@@ -124,46 +136,118 @@ func transWhereFieldExpression(ctx ParserContext, asts AstSlice) (AstSlice, erro
 	}}, nil
 }
 
-func whereFieldExpression() ParserFn {
+func expressionBinaryOperator() ParserFn {
 	return Trans(
+		First(
+			Seq("||"),
+			CharClass("*", "/", "+", "-"),
+		),
+		ChangeClassName("soql:ExprBinaryOp"),
+	)
+}
+
+func operandExpressionLeaf(isLeftHandSide bool) ParserFn {
+	return spanned(Trans(
 		FlatGroup(
-			notAheadReservedKeywords(),
-			Trans(
-				FlatGroup(
-					First(
-						selectFieldFunctionCall(),
-						complexSymbolName(),
-						Error("Unexpected token aheads near by the 'where' clause (unknown operand1)"),
-					),
-					// Dummy alias name
-					Zero(Ast{Value: ""}),
+			If(isLeftHandSide,
+				First(
+					selectFieldFunctionCall(),
+					complexSymbolName(),
+					literalValue(),
+				),
+				First(
+					literalValue(),
+					selectFieldFunctionCall(),
+					complexSymbolName(),
 				),
-				transComplexSelectFieldName,
+			),
+			// Dummy alias name
+			Zero(Ast{Value: ""}),
+		),
+		transComplexSelectFieldName,
+	))
+}
+
+func operandExpressionTerm(isLeftHandSide bool) ParserFn {
+	return First(
+		operandExpressionLeaf(isLeftHandSide),
+		spanned(FlatGroup(
+			erase(CharClass("(")),
+			sp0(),
+			Indirect(func() ParserFn { return operandExpression(isLeftHandSide) }),
+			erase(CharClass(")")),
+			sp0(),
+		)),
+		FlatGroup(
+			Trans(
+				CharClass("-"),
+				ChangeClassName("soql:ExprUnaryOp"),
+			),
+			sp0(),
+			Indirect(func() ParserFn { return operandExpressionTerm(isLeftHandSide) }),
+		),
+	)
+}
+
+func operandExpressionInnerRoot(isLeftHandSide bool) ParserFn {
+	return FlatGroup(
+		operandExpressionTerm(isLeftHandSide),
+		ZeroOrMoreTimes(
+			sp0(),
+			expressionBinaryOperator(),
+			sp0(),
+			operandExpressionTerm(isLeftHandSide),
+		),
+	)
+}
+
+// Operand of the conditional expression (e.g. `Amount * 1.1`, `FirstName || ' ' || LastName`).
+// The operators are represented as the function (e.g. `+(Amount, 1)`).
+func operandExpression(isLeftHandSide bool) ParserFn {
+	return Trans(
+		operandExpressionInnerRoot(isLeftHandSide),
+		operandExpressionExprProdRule,
+	)
+}
+
+func whereFieldExpression() ParserFn {
+	return positioned(Trans(
+		FlatGroup(
+			notAheadReservedKeywords(),
+			First(
+				operandExpression(true),
+				Error("Unexpected token aheads near by the 'where' clause (unknown operand1)"),
 			),
 			First(
 				conditionalOperator(),
 				Error("Unexpected token aheads near by the 'where' clause (unknown operator)"),
 			),
 			sp0(),
-			Trans(
-				FlatGroup(
-					// TODO: operators (* / + - ||) and parentheses
-					First(
-						literalValue(),
-						subQuery(),
-						selectFieldFunctionCall(),
-						complexSymbolName(),
-						listValue(),
-						Error("Unexpected token aheads near by the 'where' clause (unknown operand2)"),
+			First(
+				spanned(Trans(
+					FlatGroup(
+						First(
+							subQuery(),
+							FlatGroup(
+								listValue(),
+								LookAheadN(expressionBinaryOperator()),
+							),
+						),
+						// Dummy alias name
+						Zero(Ast{Value: ""}),
 					),
-					// Dummy alias name
-					Zero(Ast{Value: ""}),
+					transComplexSelectFieldName,
+				)),
+				FlatGroup(
+					notAheadReservedKeywords(),
+					notAheadLogicalOperators(),
+					operandExpression(false),
 				),
-				transComplexSelectFieldName,
+				Error("Unexpected token aheads near by the 'where' clause (unknown operand2)"),
 			),
 		),
 		transWhereFieldExpression,
-	)
+	))
 }
 
 func whereConditionExpressionInnerRoot() ParserFn {
@@ -178,6 +262,15 @@ func whereConditionExpressionInnerRoot() ParserFn {
 		),
 		First(
 			FlatGroup(
+				// Parenthesized operand expression (e.g. `(Amount + 1) * 2 > 10`)
+				LookAhead(CharClass("(")),
+				LookAhead(
+					operandExpression(true),
+					conditionalOperator(),
+				),
+				whereFieldExpression(),
+			),
+			spanned(FlatGroup(
 				erase(CharClass("(")),
 				First(
 					FlatGroup(
@@ -188,7 +281,7 @@ func whereConditionExpressionInnerRoot() ParserFn {
 					),
 					Error("Unexpected token aheads near by the 'where' clause"),
 				),
-			),
+			)),
 			whereFieldExpression(),
 		),
 		ZeroOrMoreTimes(
@@ -246,6 +339,215 @@ func whereClause() ParserFn {
 	)
 }
 
+func dataCategoryOperator() ParserFn {
+	return Trans(
+		FlatGroup(
+			First(
+				SeqI("above_or_below"),
+				SeqI("above"),
+				SeqI("below"),
+				SeqI("at"),
+			),
+			wordBoundary(),
+		),
+		func(ctx ParserContext, asts AstSlice) (AstSlice, error) {
+			var op SoqlDataCategoryOperator
+			switch strings.ToLower(asts[0].Value.(string)) {
+			case "at":
+				op = SoqlDataCategoryOperator_At
+			case "above":
+				op = SoqlDataCategoryOperator_Above
+			case "below":
+				op = SoqlDataCategoryOperator_Below
+			case "above_or_below":
+				op = SoqlDataCategoryOperator_AboveOrBelow
+			}
+			return AstSlice{{
+				ClassName: "soql:DataCategoryOperator",
+				Type:      AstType_Any,
+				Value:     op,
+			}}, nil
+		},
+	)
+}
+
+func dataCategoryFilter() ParserFn {
+	return spanned(Trans(
+		FlatGroup(
+			symbolName(),
+			sp1(),
+			First(
+				FlatGroup(
+					dataCategoryOperator(),
+					sp0(),
+					First(
+						FlatGroup(
+							erase(CharClass("(")),
+							sp0(),
+							symbolName(),
+							sp0(),
+							ZeroOrMoreTimes(
+								erase(CharClass(",")),
+								sp0(),
+								symbolName(),
+								sp0(),
+							),
+							erase(CharClass(")")),
+							sp0(),
+						),
+						FlatGroup(
+							symbolName(),
+							sp0(),
+						),
+					),
+				),
+				Error("Unexpected token aheads near by the 'with data category' clause"),
+			),
+		),
+		func(ctx ParserContext, asts AstSlice) (AstSlice, error) {
+			astsLen := len(asts)
+			categories := make([]string, astsLen-2, astsLen-2)
+			for i := 2; i < astsLen; i++ {
+				categories[i-2] = asts[i].Value.(string)
+			}
+			return AstSlice{{
+				ClassName: "soql:DataCategoryFilter",
+				Type:      AstType_Any,
+				Value: SoqlDataCategoryFilter{
+					GroupName:  asts[0].Value.(string),
+					Operator:   asts[1].Value.(SoqlDataCategoryOperator),
+					Categories: categories,
+				},
+			}}, nil
+		},
+	))
+}
+
+func withClause() ParserFn {
+	return Trans(
+		FlatGroup(
+			erase(SeqI("with")),
+			sp1(),
+			First(
+				Trans(
+					FlatGroup(
+						erase(SeqI("data")),
+						sp1(),
+						erase(SeqI("category")),
+						sp1(),
+						First(
+							FlatGroup(
+								dataCategoryFilter(),
+								ZeroOrMoreTimes(
+									erase(SeqI("and")),
+									wordBoundary(),
+									sp0(),
+									First(
+										dataCategoryFilter(),
+										Error("Unexpected token aheads near by the 'with data category' clause"),
+									),
+								),
+							),
+							Error("Unexpected token aheads near by the 'with data category' clause"),
+						),
+					),
+					func(ctx ParserContext, asts AstSlice) (AstSlice, error) {
+						astsLen := len(asts)
+						filters := make([]SoqlDataCategoryFilter, astsLen, astsLen)
+						for i := 0; i < astsLen; i++ {
+							filters[i] = asts[i].Value.(SoqlDataCategoryFilter)
+						}
+						return AstSlice{{
+							ClassName: "soql:With",
+							Type:      AstType_Any,
+							Value: SoqlWithClause{
+								DataCategory: filters,
+							},
+						}}, nil
+					},
+				),
+				Trans(
+					FlatGroup(
+						First(
+							SeqI("security_enforced"),
+							SeqI("user_mode"),
+							SeqI("system_mode"),
+						),
+						wordBoundary(),
+						sp0(),
+					),
+					func(ctx ParserContext, asts AstSlice) (AstSlice, error) {
+						z := SoqlWithClause{}
+						switch strings.ToLower(asts[0].Value.(string)) {
+						case "security_enforced":
+							z.SecurityEnforced = true
+						case "user_mode":
+							z.UserMode = true
+						case "system_mode":
+							z.SystemMode = true
+						}
+						return AstSlice{{
+							ClassName: "soql:With",
+							Type:      AstType_Any,
+							Value:     z,
+						}}, nil
+					},
+				),
+				Error("Unexpected token aheads near by the 'with' clause"),
+			),
+		),
+	)
+}
+
+func withClauses() ParserFn {
+	return positioned(Trans(
+		ZeroOrMoreTimes(spanned(withClause())),
+		func(ctx ParserContext, asts AstSlice) (AstSlice, error) {
+			z := SoqlWithClause{}
+			for i := 0; i < len(asts); i++ {
+				w := asts[i].Value.(SoqlWithClause)
+				if w.DataCategory != nil {
+					if z.DataCategory != nil {
+						return nil, errorAt(asts[i], "Duplicate 'with data category' clause found")
+					}
+					z.DataCategory = w.DataCategory
+				} else {
+					if z.SecurityEnforced || z.UserMode || z.SystemMode {
+						return nil, errorAt(asts[i], "Duplicate 'with' clause found")
+					}
+					z.SecurityEnforced = w.SecurityEnforced
+					z.UserMode = w.UserMode
+					z.SystemMode = w.SystemMode
+				}
+			}
+			return AstSlice{{
+				ClassName: "soql:With",
+				Type:      AstType_Any,
+				Value:     z,
+			}}, nil
+		},
+	))
+}
+
+func groupByFieldList() ParserFn {
+	return FlatGroup(
+		spanned(First(
+			selectFieldFunctionCall(), // e.g. HOUR_IN_DAY(convertTimezone(CreatedDate))
+			complexSymbolName(),
+		)),
+		sp0(),
+		ZeroOrMoreTimes(
+			erase(CharClass(",")),
+			sp0(),
+			spanned(First(
+				selectFieldFunctionCall(),
+				complexSymbolName(),
+			)),
+			sp0(),
+		),
+	)
+}
+
 func groupByClause() ParserFn {
 	return Trans(
 		FlatGroup(
@@ -255,42 +557,71 @@ func groupByClause() ParserFn {
 				FlatGroup(
 					erase(SeqI("by")),
 					sp1(),
-					complexSymbolName(), // TODO: scaler functions e.g. HOUR_IN_DAY(convertTimezone(CreatedDate))
-					sp0(),
-					ZeroOrMoreTimes(
-						erase(CharClass(",")),
-						sp0(),
-						complexSymbolName(), // TODO: scaler functions e.g. HOUR_IN_DAY(convertTimezone(CreatedDate))
-						sp0(),
+					First(
+						FlatGroup(
+							First(
+								FlatGroup(
+									erase(SeqI("rollup")),
+									Zero(Ast{Value: SoqlGroupingMode_Rollup}),
+								),
+								FlatGroup(
+									erase(SeqI("cube")),
+									Zero(Ast{Value: SoqlGroupingMode_Cube}),
+								),
+							),
+							sp0(),
+							erase(CharClass("(")),
+							sp0(),
+							groupByFieldList(),
+							First(
+								erase(CharClass(")")),
+								Error("Unexpected token aheads near by the 'group by' clause"),
+							),
+							sp0(),
+						),
+						FlatGroup(
+							Zero(Ast{Value: SoqlGroupingMode_Default}),
+							groupByFieldList(),
+						),
 					),
 				),
 				Error("Unexpected token aheads near by the 'group by' clause"),
 			),
 		),
 		func(ctx ParserContext, asts AstSlice) (AstSlice, error) {
-			astsLen := len(asts)
+			astsLen := len(asts) - 1
 			fields := make([]SoqlFieldInfo, astsLen, astsLen)
 			for i := 0; i < astsLen; i++ {
-				fields[i] = SoqlFieldInfo{
-					Type: SoqlFieldInfo_Field,
-					Name: asts[i].Value.([]string),
+				switch asts[i+1].ClassName {
+				case class.SelectFieldFunctionCall:
+					fields[i] = asts[i+1].Value.(SoqlFieldInfo)
+				default:
+					fields[i] = SoqlFieldInfo{
+						Type: SoqlFieldInfo_Field,
+						Name: asts[i+1].Value.([]string),
+						Span: spanOf(asts[i+1]),
+					}
 				}
 			}
 			return AstSlice{{
 				ClassName: "soql:GroupBy",
 				Type:      AstType_Any,
 				Value:     fields,
+			}, {
+				ClassName: "soql:GroupingMode",
+				Type:      AstType_Any,
+				Value:     asts[0].Value,
 			}}, nil
 		},
 	)
 }
 
 func havingFieldExpression() ParserFn {
-	return Trans(
+	return positioned(Trans(
 		FlatGroup(
 			notAheadReservedKeywords(),
 			First(
-				selectFieldFunctionCall(),
+				operandExpression(true),
 				Error("Unexpected token aheads near by the 'having' clause (unknown operand1)"),
 			),
 			First(
@@ -298,23 +629,31 @@ func havingFieldExpression() ParserFn {
 				Error("Unexpected token aheads near by the 'having' clause (unknown operator)"),
 			),
 			sp0(),
-			Trans(
-				FlatGroup(
-					First(
-						literalValue(),
-						subQuery(),
-						selectFieldFunctionCall(),
-						listValue(),
-						Error("Unexpected token aheads near by the 'having' clause (unknown operand2)"),
+			First(
+				spanned(Trans(
+					FlatGroup(
+						First(
+							subQuery(),
+							FlatGroup(
+								listValue(),
+								LookAheadN(expressionBinaryOperator()),
+							),
+						),
+						// Dummy alias name
+						Zero(Ast{Value: ""}),
 					),
-					// Dummy alias name
-					Zero(Ast{Value: ""}),
+					transComplexSelectFieldName,
+				)),
+				FlatGroup(
+					notAheadReservedKeywords(),
+					notAheadLogicalOperators(),
+					operandExpression(false),
 				),
-				transComplexSelectFieldName,
+				Error("Unexpected token aheads near by the 'having' clause (unknown operand2)"),
 			),
 		),
 		transWhereFieldExpression,
-	)
+	))
 }
 
 func havingConditionExpressionInnerRoot() ParserFn {
@@ -330,6 +669,15 @@ func havingConditionExpressionInnerRoot() ParserFn {
 			),
 			First(
 				FlatGroup(
+					// Parenthesized operand expression (e.g. `(SUM(Amount) + 1) * 2 > 10`)
+					LookAhead(CharClass("(")),
+					LookAhead(
+						operandExpression(true),
+						conditionalOperator(),
+					),
+					havingFieldExpression(),
+				),
+				spanned(FlatGroup(
 					erase(CharClass("(")),
 					First(
 						FlatGroup(
@@ -340,7 +688,7 @@ func havingConditionExpressionInnerRoot() ParserFn {
 						),
 						Error("Unexpected token aheads near by the 'having' clause"),
 					),
-				),
+				)),
 				havingFieldExpression(),
 			),
 			ZeroOrMoreTimes(
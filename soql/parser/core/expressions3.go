@@ -64,6 +64,32 @@ func orderByNulls() ParserFn {
 	)
 }
 
+// Item of the order by clause. The span is set to the resulting SoqlOrderByInfo.
+func orderByItem() ParserFn {
+	return spanned(Trans(
+		FlatGroup(
+			spanned(complexSymbolName()),
+			orderByDirection(),
+			orderByNulls(),
+		),
+		func(ctx ParserContext, asts AstSlice) (AstSlice, error) {
+			return AstSlice{{
+				ClassName: "soql:OrderByItem",
+				Type:      AstType_Any,
+				Value: SoqlOrderByInfo{
+					Field: SoqlFieldInfo{
+						Type: SoqlFieldInfo_Field,
+						Name: asts[0].Value.([]string),
+						Span: spanOf(asts[0]),
+					},
+					Desc:      asts[1].Value.(bool),
+					NullsLast: asts[2].Value.(bool),
+				},
+			}}, nil
+		},
+	))
+}
+
 func orderByClause() ParserFn {
 	return Trans(
 		FlatGroup(
@@ -76,9 +102,7 @@ func orderByClause() ParserFn {
 							sp1(),
 							erase(SeqI("by")),
 							sp1(),
-							complexSymbolName(),
-							orderByDirection(),
-							orderByNulls(),
+							orderByItem(),
 						),
 						Error("Unexpected token aheads near by the 'order by' clause"),
 					),
@@ -87,9 +111,7 @@ func orderByClause() ParserFn {
 						First(
 							FlatGroup(
 								sp0(),
-								complexSymbolName(),
-								orderByDirection(),
-								orderByNulls(),
+								orderByItem(),
 							),
 							Error("Unexpected token aheads near by the 'order by' clause"),
 						),
@@ -99,17 +121,10 @@ func orderByClause() ParserFn {
 			),
 		),
 		func(ctx ParserContext, asts AstSlice) (AstSlice, error) {
-			astsLen := len(asts) / 3
+			astsLen := len(asts)
 			z := make([]SoqlOrderByInfo, astsLen, astsLen)
 			for i := 0; i < astsLen; i++ {
-				z[i] = SoqlOrderByInfo{
-					Field: SoqlFieldInfo{
-						Type: SoqlFieldInfo_Field,
-						Name: asts[i*3].Value.([]string),
-					},
-					Desc:      asts[i*3+1].Value.(bool),
-					NullsLast: asts[i*3+2].Value.(bool),
-				}
+				z[i] = asts[i].Value.(SoqlOrderByInfo)
 			}
 			return AstSlice{{
 				ClassName: "soql:OrderBy",
@@ -164,6 +179,80 @@ func limitClause() ParserFn {
 	)
 }
 
+func offsetAndLimitClause() ParserFn {
+	return First(
+		Trans(
+			FlatGroup(
+				offsetClause(),
+				First(
+					limitClause(),
+					Zero(Ast{Value: int64(0)}),
+				),
+			),
+			func(ctx ParserContext, asts AstSlice) (AstSlice, error) {
+				offsetAndLimit := SoqlOffsetAndLimitClause{}
+
+				switch asts[0].Value.(type) {
+				case string:
+					offsetAndLimit.OffsetParamName = asts[0].Value.(string)
+				default:
+					offsetAndLimit.Offset = asts[0].Value.(int64)
+				}
+
+				switch asts[1].Value.(type) {
+				case string:
+					offsetAndLimit.LimitParamName = asts[1].Value.(string)
+				default:
+					offsetAndLimit.Limit = asts[1].Value.(int64)
+				}
+
+				return AstSlice{{
+					ClassName: "soql:LimitAndOffset",
+					Type:      AstType_Any,
+					Value:     offsetAndLimit,
+				}}, nil
+			},
+		),
+		Trans(
+			FlatGroup(
+				limitClause(),
+				First(
+					offsetClause(),
+					Zero(Ast{Value: int64(0)}),
+				),
+			),
+			func(ctx ParserContext, asts AstSlice) (AstSlice, error) {
+				offsetAndLimit := SoqlOffsetAndLimitClause{}
+
+				switch asts[1].Value.(type) {
+				case string:
+					offsetAndLimit.OffsetParamName = asts[1].Value.(string)
+				default:
+					offsetAndLimit.Offset = asts[1].Value.(int64)
+				}
+
+				switch asts[0].Value.(type) {
+				case string:
+					offsetAndLimit.LimitParamName = asts[0].Value.(string)
+				default:
+					offsetAndLimit.Limit = asts[0].Value.(int64)
+				}
+
+				return AstSlice{{
+					ClassName: "soql:LimitAndOffset",
+					Type:      AstType_Any,
+					Value:     offsetAndLimit,
+				}}, nil
+			},
+		),
+		Zero(Ast{
+			ClassName: "soql:LimitAndOffset",
+			Type:      AstType_Any,
+			Value:     SoqlOffsetAndLimitClause{},
+		}),
+	)
+}
+
 func forViewClause() ParserFn {
 	return Trans(
 		FlatGroup(
@@ -289,18 +378,16 @@ func selectStatementInner(isSubQuery bool) ParserFn {
 				fromClause(),
 				Error("The 'from' clause is expected"),
 			),
-			// TODO: `using scope` clause (USING SCOPE filterScope)
 			First(
 				whereClause(),
 				Zero(Ast{
 					ClassName: "soql:Where",
 				}),
 			),
-			// TODO: `with` clause (WITH SECURITY_ENFORCED, WITH RecordVisibilityContext (...), WITH _filteringExpression_)
-			// TODO: `with` clause (WITH DATA CATEGORY _filteringExpression_)
+			withClauses(),
+			// TODO: `with` clause (WITH RecordVisibilityContext (...))
 			First(
 				FlatGroup(
-					// TODO: `group by rollup`, `group by cube` clause
 					groupByClause(),
 					First(
 						havingClause(),
@@ -313,6 +400,11 @@ func selectStatementInner(isSubQuery bool) ParserFn {
 					Zero(Ast{
 						ClassName: "soql:GroupBy",
 					}),
+					Zero(Ast{
+						ClassName: "soql:GroupingMode",
+						Type:      AstType_Any,
+						Value:     SoqlGroupingMode_Default,
+					}),
 					Zero(Ast{
 						ClassName: "soql:Having",
 					}),
@@ -324,77 +416,7 @@ func selectStatementInner(isSubQuery bool) ParserFn {
 					ClassName: "soql:OrderBy",
 				}),
 			),
-			First(
-				Trans(
-					FlatGroup(
-						offsetClause(),
-						First(
-							limitClause(),
-							Zero(Ast{Value: int64(0)}),
-						),
-					),
-					func(ctx ParserContext, asts AstSlice) (AstSlice, error) {
-						offsetAndLimit := SoqlOffsetAndLimitClause{}
-
-						switch asts[0].Value.(type) {
-						case string:
-							offsetAndLimit.OffsetParamName = asts[0].Value.(string)
-						default:
-							offsetAndLimit.Offset = asts[0].Value.(int64)
-						}
-
-						switch asts[1].Value.(type) {
-						case string:
-							offsetAndLimit.LimitParamName = asts[1].Value.(string)
-						default:
-							offsetAndLimit.Limit = asts[1].Value.(int64)
-						}
-
-						return AstSlice{{
-							ClassName: "soql:LimitAndOffset",
-							Type:      AstType_Any,
-							Value:     offsetAndLimit,
-						}}, nil
-					},
-				),
-				Trans(
-					FlatGroup(
-						limitClause(),
-						First(
-							offsetClause(),
-							Zero(Ast{Value: int64(0)}),
-						),
-					),
-					func(ctx ParserContext, asts AstSlice) (AstSlice, error) {
-						offsetAndLimit := SoqlOffsetAndLimitClause{}
-
-						switch asts[1].Value.(type) {
-						case string:
-							offsetAndLimit.OffsetParamName = asts[1].Value.(string)
-						default:
-							offsetAndLimit.Offset = asts[1].Value.(int64)
-						}
-
-						switch asts[0].Value.(type) {
-						case string:
-							offsetAndLimit.LimitParamName = asts[0].Value.(string)
-						default:
-							offsetAndLimit.Limit = asts[0].Value.(int64)
-						}
-
-						return AstSlice{{
-							ClassName: "soql:LimitAndOffset",
-							Type:      AstType_Any,
-							Value:     offsetAndLimit,
-						}}, nil
-					},
-				),
-				Zero(Ast{
-					ClassName: "soql:LimitAndOffset",
-					Type:      AstType_Any,
-					Value:     SoqlOffsetAndLimitClause{},
-				}),
-			),
+			offsetAndLimitClause(),
 			First(
 				forViewClause(),
 				forUpdateClause(),
@@ -409,6 +431,32 @@ func selectStatementInner(isSubQuery bool) ParserFn {
 					Value:     SoqlForClause{},
 				}),
 			),
+			If(isSubQuery,
+				Zero(Ast{
+					ClassName: "soql:AllRows",
+					Type:      AstType_Bool,
+					Value:     false,
+				}),
+				First(
+					FlatGroup(
+						erase(SeqI("all")),
+						sp1(),
+						erase(SeqI("rows")),
+						wordBoundary(),
+						sp0(),
+						Zero(Ast{
+							ClassName: "soql:AllRows",
+							Type:      AstType_Bool,
+							Value:     true,
+						}),
+					),
+					Zero(Ast{
+						ClassName: "soql:AllRows",
+						Type:      AstType_Bool,
+						Value:     false,
+					}),
+				),
+			),
 			LookAhead(
 				sp0(),
 				First(
@@ -438,15 +486,15 @@ func selectStatementInner(isSubQuery bool) ParserFn {
 			if asts[2].Value != nil {
 				qWhere = asts[2].Value.([]SoqlCondition)
 			}
-			if asts[3].Value != nil {
-				qGroupBy = asts[3].Value.([]SoqlFieldInfo)
+			if asts[4].Value != nil {
+				qGroupBy = asts[4].Value.([]SoqlFieldInfo)
 				isAggregation = true
 			}
-			if asts[4].Value != nil {
-				qHaving = asts[4].Value.([]SoqlCondition)
+			if asts[6].Value != nil {
+				qHaving = asts[6].Value.([]SoqlCondition)
 			}
-			if asts[5].Value != nil {
-				qOrderBy = asts[5].Value.([]SoqlOrderByInfo)
+			if asts[7].Value != nil {
+				qOrderBy = asts[7].Value.([]SoqlOrderByInfo)
 			}
 
 			return AstSlice{{
@@ -456,11 +504,14 @@ func selectStatementInner(isSubQuery bool) ParserFn {
 					Fields:         qFields,
 					From:           qFrom,
 					Where:          qWhere,
+					With:           asts[3].Value.(SoqlWithClause),
 					GroupBy:        qGroupBy,
+					GroupingMode:   asts[5].Value.(SoqlGroupingMode),
 					Having:         qHaving,
 					OrderBy:        qOrderBy,
-					OffsetAndLimit: asts[6].Value.(SoqlOffsetAndLimitClause),
-					For:            asts[7].Value.(SoqlForClause),
+					OffsetAndLimit: asts[8].Value.(SoqlOffsetAndLimitClause),
+					For:            asts[9].Value.(SoqlForClause),
+					AllRows:        asts[10].Value.(bool),
 					IsAggregation:  isAggregation,
 				},
 			}}, nil
@@ -0,0 +1,563 @@
+package core
+
+import (
+	"strings"
+
+	. "github.com/shellyln/go-open-soql-parser/soql/parser/types"
+	. "github.com/shellyln/takenoco/base"
+	. "github.com/shellyln/takenoco/string"
+)
+
+func soslSearchQuery() ParserFn {
+	return First(
+		Trans(
+			FlatGroup(
+				erase(CharClass("{")),
+				ZeroOrMoreTimes(
+					First(
+						// The escape sequences are interpreted by the search engine.
+						FlatGroup(
+							Seq("\\"),
+							First(
+								Any(),
+								FlatGroup(End(), Error("An unexpected termination has appeared in the search query.")),
+							),
+						),
+						OneOrMoreTimes(CharClassN("}", "\\")),
+					),
+				),
+				First(
+					FlatGroup(End(), Error("An unexpected termination has appeared in the search query.")),
+					erase(CharClass("}")),
+				),
+			),
+			Concat,
+		),
+		stringValue(),
+	)
+}
+
+func soslInClause() ParserFn {
+	return Trans(
+		FlatGroup(
+			erase(SeqI("in")),
+			sp1(),
+			First(
+				FlatGroup(
+					First(
+						SeqI("all"),
+						SeqI("name"),
+						SeqI("email"),
+						SeqI("phone"),
+						SeqI("sidebar"),
+					),
+					sp1(),
+					erase(SeqI("fields")),
+					wordBoundary(),
+					sp0(),
+				),
+				Error("Unexpected token aheads near by the 'in' clause"),
+			),
+		),
+		func(ctx ParserContext, asts AstSlice) (AstSlice, error) {
+			var z SoslSearchGroup
+			switch strings.ToLower(asts[0].Value.(string)) {
+			case "name":
+				z = SoslSearchGroup_NameFields
+			case "email":
+				z = SoslSearchGroup_EmailFields
+			case "phone":
+				z = SoslSearchGroup_PhoneFields
+			case "sidebar":
+				z = SoslSearchGroup_SidebarFields
+			default:
+				z = SoslSearchGroup_AllFields
+			}
+			return AstSlice{{
+				ClassName: "sosl:In",
+				Type:      AstType_Any,
+				Value:     z,
+			}}, nil
+		},
+	)
+}
+
+func returningObject() ParserFn {
+	return Trans(
+		FlatGroup(
+			notAheadReservedKeywords(),
+			spanned(complexSymbolName()),
+			First(
+				FlatGroup(
+					erase(CharClass("(")),
+					sp0(),
+					selectFieldList(),
+					First(
+						whereClause(),
+						Zero(Ast{
+							ClassName: "soql:Where",
+						}),
+					),
+					First(
+						orderByClause(),
+						Zero(Ast{
+							ClassName: "soql:OrderBy",
+						}),
+					),
+					offsetAndLimitClause(),
+					First(
+						erase(CharClass(")")),
+						Error("Unexpected token aheads near by the 'returning' clause"),
+					),
+					sp0(),
+				),
+				FlatGroup(
+					Zero(Ast{
+						ClassName: "soql:SelectFieldList",
+					}),
+					Zero(Ast{
+						ClassName: "soql:Where",
+					}),
+					Zero(Ast{
+						ClassName: "soql:OrderBy",
+					}),
+					Zero(Ast{
+						ClassName: "soql:LimitAndOffset",
+						Type:      AstType_Any,
+						Value:     SoqlOffsetAndLimitClause{},
+					}),
+				),
+			),
+		),
+		func(ctx ParserContext, asts AstSlice) (AstSlice, error) {
+			var qFields []SoqlFieldInfo
+			var qWhere []SoqlCondition
+			var qOrderBy []SoqlOrderByInfo
+
+			if asts[1].Value != nil {
+				qFields = asts[1].Value.([]SoqlFieldInfo)
+			} else {
+				// The object that has no field list returns the record ids.
+				qFields = []SoqlFieldInfo{{
+					Type: SoqlFieldInfo_Field,
+					Name: []string{"Id"},
+					Span: spanOf(asts[0]),
+				}}
+			}
+			if asts[2].Value != nil {
+				qWhere = asts[2].Value.([]SoqlCondition)
+			}
+			if asts[3].Value != nil {
+				qOrderBy = asts[3].Value.([]SoqlOrderByInfo)
+			}
+
+			return AstSlice{{
+				ClassName: "soql:Query",
+				Type:      AstType_Any,
+				Value: SoqlQuery{
+					Fields: qFields,
+					From: []SoqlObjectInfo{{
+						Name: asts[0].Value.([]string),
+						Span: spanOf(asts[0]),
+					}},
+					Where:          qWhere,
+					OrderBy:        qOrderBy,
+					OffsetAndLimit: asts[4].Value.(SoqlOffsetAndLimitClause),
+				},
+			}}, nil
+		},
+	)
+}
+
+func returningClause() ParserFn {
+	return Trans(
+		FlatGroup(
+			erase(SeqI("returning")),
+			sp1(),
+			First(
+				returningObject(),
+				Error("Unexpected token aheads near by the 'returning' clause"),
+			),
+			ZeroOrMoreTimes(
+				erase(CharClass(",")),
+				sp0(),
+				First(
+					returningObject(),
+					Error("Unexpected token aheads near by the 'returning' clause"),
+				),
+			),
+		),
+		func(ctx ParserContext, asts AstSlice) (AstSlice, error) {
+			astsLen := len(asts)
+			z := make([]SoqlQuery, astsLen, astsLen)
+			for i := 0; i < astsLen; i++ {
+				z[i] = asts[i].Value.(SoqlQuery)
+			}
+			return AstSlice{{
+				ClassName: "sosl:Returning",
+				Type:      AstType_Any,
+				Value:     z,
+			}}, nil
+		},
+	)
+}
+
+func soslWithStringOption(name string, fn func(z *SoslWithClause, v string)) ParserFn {
+	return Trans(
+		FlatGroup(
+			erase(SeqI(name)),
+			sp0(),
+			First(
+				FlatGroup(
+					erase(CharClass("=")),
+					sp0(),
+					stringValue(),
+					sp0(),
+				),
+				Error("Unexpected token aheads near by the 'with "+name+"' clause"),
+			),
+		),
+		func(ctx ParserContext, asts AstSlice) (AstSlice, error) {
+			z := SoslWithClause{}
+			fn(&z, asts[0].Value.(string))
+			return AstSlice{{
+				ClassName: "sosl:With",
+				Type:      AstType_Any,
+				Value:     z,
+			}}, nil
+		},
+	)
+}
+
+func soslWithClause() ParserFn {
+	return FlatGroup(
+		erase(SeqI("with")),
+		sp1(),
+		First(
+			soslWithStringOption("division", func(z *SoslWithClause, v string) {
+				z.Division = v
+			}),
+			soslWithStringOption("metadata", func(z *SoslWithClause, v string) {
+				z.Metadata = v
+			}),
+			soslWithStringOption("pricebookid", func(z *SoslWithClause, v string) {
+				z.PricebookId = v
+			}),
+			Trans(
+				FlatGroup(
+					erase(SeqI("highlight")),
+					wordBoundary(),
+					sp0(),
+				),
+				func(ctx ParserContext, asts AstSlice) (AstSlice, error) {
+					return AstSlice{{
+						ClassName: "sosl:With",
+						Type:      AstType_Any,
+						Value:     SoslWithClause{Highlight: true},
+					}}, nil
+				},
+			),
+			Trans(
+				FlatGroup(
+					erase(SeqI("snippet")),
+					wordBoundary(),
+					sp0(),
+					First(
+						FlatGroup(
+							erase(CharClass("(")),
+							sp0(),
+							First(
+								FlatGroup(
+									erase(SeqI("target_length")),
+									sp0(),
+									erase(CharClass("=")),
+									sp0(),
+									decimalIntegerValue(),
+									sp0(),
+									erase(CharClass(")")),
+									sp0(),
+								),
+								Error("Unexpected token aheads near by the 'with snippet' clause"),
+							),
+						),
+						Zero(Ast{Value: int64(0)}),
+					),
+				),
+				func(ctx ParserContext, asts AstSlice) (AstSlice, error) {
+					return AstSlice{{
+						ClassName: "sosl:With",
+						Type:      AstType_Any,
+						Value: SoslWithClause{
+							Snippet:             true,
+							SnippetTargetLength: asts[0].Value.(int64),
+						},
+					}}, nil
+				},
+			),
+			Trans(
+				FlatGroup(
+					erase(SeqI("spell_correction")),
+					sp0(),
+					First(
+						FlatGroup(
+							erase(CharClass("=")),
+							sp0(),
+							First(
+								trueValue(),
+								falseValue(),
+							),
+							sp0(),
+						),
+						Error("Unexpected token aheads near by the 'with spell_correction' clause"),
+					),
+				),
+				func(ctx ParserContext, asts AstSlice) (AstSlice, error) {
+					return AstSlice{{
+						ClassName: "sosl:With",
+						Type:      AstType_Any,
+						Value: SoslWithClause{
+							NoSpellCorrection: !asts[0].Value.(bool),
+						},
+					}}, nil
+				},
+			),
+			Trans(
+				FlatGroup(
+					erase(SeqI("network")),
+					sp0(),
+					First(
+						FlatGroup(
+							erase(CharClass("=")),
+							sp0(),
+							stringValue(),
+							sp0(),
+						),
+						FlatGroup(
+							erase(SeqI("in")),
+							sp0(),
+							erase(CharClass("(")),
+							sp0(),
+							stringValue(),
+							sp0(),
+							ZeroOrMoreTimes(
+								erase(CharClass(",")),
+								sp0(),
+								stringValue(),
+								sp0(),
+							),
+							erase(CharClass(")")),
+							sp0(),
+						),
+						Error("Unexpected token aheads near by the 'with network' clause"),
+					),
+				),
+				func(ctx ParserContext, asts AstSlice) (AstSlice, error) {
+					astsLen := len(asts)
+					network := make([]string, astsLen, astsLen)
+					for i := 0; i < astsLen; i++ {
+						network[i] = asts[i].Value.(string)
+					}
+					return AstSlice{{
+						ClassName: "sosl:With",
+						Type:      AstType_Any,
+						Value:     SoslWithClause{Network: network},
+					}}, nil
+				},
+			),
+		),
+	)
+}
+
+func soslWithClauses() ParserFn {
+	return positioned(Trans(
+		ZeroOrMoreTimes(
+			spanned(First(
+				soslWithClause(),
+				withClause(),
+			)),
+		),
+		func(ctx ParserContext, asts AstSlice) (AstSlice, error) {
+			z := SoslWithClause{}
+			for i := 0; i < len(asts); i++ {
+				switch w := asts[i].Value.(type) {
+				case SoqlWithClause:
+					if w.DataCategory != nil {
+						if z.DataCategory != nil {
+							return nil, errorAt(asts[i], "Duplicate 'with data category' clause found")
+						}
+						z.DataCategory = w.DataCategory
+					} else {
+						if z.SecurityEnforced || z.UserMode || z.SystemMode {
+							return nil, errorAt(asts[i], "Duplicate 'with' clause found")
+						}
+						z.SecurityEnforced = w.SecurityEnforced
+						z.UserMode = w.UserMode
+						z.SystemMode = w.SystemMode
+					}
+				case SoslWithClause:
+					dup := false
+					switch {
+					case w.Division != "":
+						dup = z.Division != ""
+						z.Division = w.Division
+					case w.Metadata != "":
+						dup = z.Metadata != ""
+						z.Metadata = w.Metadata
+					case w.PricebookId != "":
+						dup = z.PricebookId != ""
+						z.PricebookId = w.PricebookId
+					case w.Highlight:
+						dup = z.Highlight
+						z.Highlight = true
+					case w.Snippet:
+						dup = z.Snippet
+						z.Snippet = true
+						z.SnippetTargetLength = w.SnippetTargetLength
+					case w.Network != nil:
+						dup = z.Network != nil
+						z.Network = w.Network
+					default:
+						// with spell_correction = true | false
+						z.NoSpellCorrection = w.NoSpellCorrection
+					}
+					if dup {
+						return nil, errorAt(asts[i], "Duplicate 'with' clause found")
+					}
+				}
+			}
+			return AstSlice{{
+				ClassName: "sosl:With",
+				Type:      AstType_Any,
+				Value:     z,
+			}}, nil
+		},
+	))
+}
+
+func soslUpdateClause() ParserFn {
+	return Trans(
+		FlatGroup(
+			erase(SeqI("update")),
+			sp1(),
+			First(
+				FlatGroup(
+					SeqI("tracking"),
+					wordBoundary(),
+					sp0(),
+					ZeroOrOnce(
+						erase(CharClass(",")),
+						sp0(),
+						SeqI("viewstat"),
+						wordBoundary(),
+						sp0(),
+					),
+				),
+				FlatGroup(
+					SeqI("viewstat"),
+					wordBoundary(),
+					sp0(),
+					ZeroOrOnce(
+						erase(CharClass(",")),
+						sp0(),
+						SeqI("tracking"),
+						wordBoundary(),
+						sp0(),
+					),
+				),
+				Error("Unexpected token aheads near by the 'update' clause"),
+			),
+		),
+		func(ctx ParserContext, asts AstSlice) (AstSlice, error) {
+			z := SoslUpdateClause{}
+			for i := 0; i < len(asts); i++ {
+				switch strings.ToLower(asts[i].Value.(string)) {
+				case "tracking":
+					z.Tracking = true
+				case "viewstat":
+					z.Viewstat = true
+				}
+			}
+			return AstSlice{{
+				ClassName: "sosl:Update",
+				Type:      AstType_Any,
+				Value:     z,
+			}}, nil
+		},
+	)
+}
+
+func findStatement() ParserFn {
+	return Trans(
+		FlatGroup(
+			erase(SeqI("find")),
+			sp0(),
+			First(
+				soslSearchQuery(),
+				Error("The search query is expected"),
+			),
+			sp0(),
+			First(
+				soslInClause(),
+				Zero(Ast{
+					ClassName: "sosl:In",
+					Type:      AstType_Any,
+					Value:     SoslSearchGroup_AllFields,
+				}),
+			),
+			First(
+				returningClause(),
+				Zero(Ast{
+					ClassName: "sosl:Returning",
+				}),
+			),
+			soslWithClauses(),
+			First(
+				limitClause(),
+				Zero(Ast{Value: int64(0)}),
+			),
+			First(
+				soslUpdateClause(),
+				Zero(Ast{
+					ClassName: "sosl:Update",
+					Type:      AstType_Any,
+					Value:     SoslUpdateClause{},
+				}),
+			),
+			LookAhead(
+				sp0(),
+				First(
+					End(),
+					Error("Unexpected token aheads"),
+				),
+			),
+		),
+		func(ctx ParserContext, asts AstSlice) (AstSlice, error) {
+			var qReturning []SoqlQuery
+
+			if asts[2].Value != nil {
+				qReturning = asts[2].Value.([]SoqlQuery)
+			}
+
+			z := SoslQuery{
+				SearchQuery: asts[0].Value.(string),
+				SearchGroup: asts[1].Value.(SoslSearchGroup),
+				Returning:   qReturning,
+				With:        asts[3].Value.(SoslWithClause),
+				Update:      asts[5].Value.(SoslUpdateClause),
+			}
+
+			switch v := asts[4].Value.(type) {
+			case string:
+				z.LimitParamName = v
+			default:
+				z.Limit = v.(int64)
+			}
+
+			return AstSlice{{
+				ClassName: "sosl:Query",
+				Type:      AstType_Any,
+				Value:     z,
+			}}, nil
+		},
+	)
+}
@@ -2,19 +2,67 @@
 package core
 
 import (
+	. "github.com/shellyln/go-open-soql-parser/soql/parser/types"
 	. "github.com/shellyln/takenoco/base"
 	. "github.com/shellyln/takenoco/string"
 )
 
+// Options of the parser. It is passed as the tag of the parser context.
+type ParserOptions struct {
+	DecimalLiteral bool // If true, the float number literals are parsed as Decimal.
+}
+
+var defaultParserOptions = &ParserOptions{}
+
+func getParserOptions(ctx ParserContext) *ParserOptions {
+	if opts, ok := ctx.Tag.(*ParserOptions); ok && opts != nil {
+		return opts
+	}
+	return defaultParserOptions
+}
+
+// Set the lines and columns of the source spans.
+// The spans are pointers, so they are updated in place.
+func transSpanPositions(ctx ParserContext, asts AstSlice) (AstSlice, error) {
+	for i := 0; i < len(asts); i++ {
+		switch v := asts[i].Value.(type) {
+		case SoqlQuery:
+			setSpanPositions(ctx.Str, &v)
+		case SoslQuery:
+			setSoslSpanPositions(ctx.Str, &v)
+		}
+	}
+	return asts, nil
+}
+
 func Query() ParserFn {
-	return FlatGroup(
-		Start(),
-		sp0(),
-		First(
-			selectStatement(),
-			Error("Unexpected token aheads"),
+	return Trans(
+		FlatGroup(
+			Start(),
+			sp0(),
+			First(
+				selectStatement(),
+				Error("Unexpected token aheads"),
+			),
+			sp0(),
+			End(),
+		),
+		transSpanPositions,
+	)
+}
+
+func Search() ParserFn {
+	return Trans(
+		FlatGroup(
+			Start(),
+			sp0(),
+			First(
+				findStatement(),
+				Error("Unexpected token aheads"),
+			),
+			sp0(),
+			End(),
 		),
-		sp0(),
-		End(),
+		transSpanPositions,
 	)
 }
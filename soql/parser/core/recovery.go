@@ -0,0 +1,140 @@
+package core
+
+import (
+	. "github.com/shellyln/takenoco/base"
+	. "github.com/shellyln/takenoco/string"
+)
+
+// Clause keywords; "GROUP" and "ORDER" without "BY" are also the clause keywords.
+var clauseKeywordParser = First(
+	reservedKeywords(),
+	FlatGroup(First(SeqI("group"), SeqI("order")), WordBoundary()),
+)
+
+var syncKeywordParser = First(
+	clauseKeywordParser,
+	logicalOperators(),
+)
+
+func matchAt(p ParserFn, s string, pt int) (int, bool) {
+	ctx := NewStringParserContext(s)
+	ctx.Position = pt
+	if out, err := p(*ctx); err == nil && out.MatchStatus == MatchStatus_Matched {
+		return out.Position, true
+	}
+	return pt, false
+}
+
+func isSymbolChar(c byte) bool {
+	return 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '_' || c == '$'
+}
+
+func skipQuoted(s string, i int, quote byte) int {
+	for i++; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+// Returns the end of the literal, quoted name, hinting string, search query or comment at the offset.
+// If there is none of them at the offset, the offset is returned.
+func skipIgnored(s string, i int) int {
+	c := s[i]
+	switch {
+	case c == '\'' || c == '"' || c == '`':
+		return skipQuoted(s, i, c)
+	case c == '{':
+		return skipQuoted(s, i, '}')
+	case c == '-' && i+1 < len(s) && s[i+1] == '-':
+		for i < len(s) && s[i] != '\n' && s[i] != '\r' {
+			i++
+		}
+	case c == '/' && i+1 < len(s) && s[i+1] == '*':
+		i += 2
+		for i < len(s) && !(s[i] == '*' && i+1 < len(s) && s[i+1] == '/') {
+			i++
+		}
+		i += 2
+	}
+	if i > len(s) {
+		return len(s)
+	}
+	return i
+}
+
+// Returns the offsets of the synchronization points for the error recovery.
+// The synchronization points are the clause keywords, logical operators (AND and OR), commas and closing parentheses.
+// Literals, quoted names, hinting strings, search queries and comments are skipped.
+func SyncPoints(s string) []int {
+	points := make([]int, 0)
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch j := skipIgnored(s, i); {
+		case j > i:
+			i = j
+		case c == ',' || c == ')':
+			points = append(points, i)
+			i++
+		case isSymbolChar(c):
+			// Names after the dot (e.g. "Account.Order") are not the keywords.
+			if i == 0 || s[i-1] != '.' {
+				if _, ok := matchAt(syncKeywordParser, s, i); ok {
+					points = append(points, i)
+				}
+			}
+			for i < len(s) && isSymbolChar(s[i]) {
+				i++
+			}
+		default:
+			i++
+		}
+	}
+
+	return points
+}
+
+// Returns the end offset of the synchronization point at the offset.
+func SyncPointEnd(s string, pt int) int {
+	if s[pt] == ',' || s[pt] == ')' {
+		return pt + 1
+	}
+	end, _ := matchAt(syncKeywordParser, s, pt)
+	return end
+}
+
+// Returns true if the synchronization point at the offset is the clause keyword.
+func IsClauseSyncPoint(s string, pt int) bool {
+	_, ok := matchAt(clauseKeywordParser, s, pt)
+	return ok
+}
+
+// Returns the offset of the last opening parenthesis that is not closed in s[start:end], or -1 if there is none.
+func UnclosedParen(s string, start, end int) int {
+	stack := make([]int, 0)
+	for i := start; i < end; {
+		switch j := skipIgnored(s, i); {
+		case j > i:
+			i = j
+		case s[i] == '(':
+			stack = append(stack, i)
+			i++
+		case s[i] == ')':
+			if len(stack) != 0 {
+				stack = stack[:len(stack)-1]
+			}
+			i++
+		default:
+			i++
+		}
+	}
+	if len(stack) == 0 {
+		return -1
+	}
+	return stack[len(stack)-1]
+}
@@ -22,6 +22,10 @@ var conditionExpressionExprRule3 = Precedence{
 				cond = append(cond, cond1...)
 				cond = append(cond, SoqlCondition{
 					Opcode: SoqlConditionOpcode_Not,
+					Span: joinSpans(
+						&SoqlSourceSpan{Offset: asts[0].Position},
+						conditionsSpan(cond1),
+					),
 				})
 
 				return AstSlice{{
@@ -53,6 +57,7 @@ var conditionExpressionExprRule2 = Precedence{
 				cond = append(cond, cond2...)
 				cond = append(cond, SoqlCondition{
 					Opcode: SoqlConditionOpcode_And,
+					Span:   joinSpans(conditionsSpan(cond1), conditionsSpan(cond2)),
 				})
 
 				return AstSlice{{
@@ -84,6 +89,7 @@ var conditionExpressionExprRule1 = Precedence{
 				cond = append(cond, cond2...)
 				cond = append(cond, SoqlCondition{
 					Opcode: SoqlConditionOpcode_Or,
+					Span:   joinSpans(conditionsSpan(cond1), conditionsSpan(cond2)),
 				})
 
 				return AstSlice{{
@@ -104,3 +110,103 @@ var conditionExpressionExprProdRule TransformerFn = ProductionRule(
 	},
 	FlatGroup(Start(), objparser.Any(), objparser.End()),
 )
+
+func transOperandExpressionUnaryOp(ctx ParserContext, asts AstSlice) (AstSlice, error) {
+	return AstSlice{{
+		ClassName: "soql:FieldInfo",
+		Type:      AstType_Any,
+		Value: SoqlFieldInfo{
+			Type:       SoqlFieldInfo_Function,
+			Name:       []string{asts[0].Value.(string)},
+			Parameters: []SoqlFieldInfo{asts[1].Value.(SoqlFieldInfo)},
+			Span: joinSpans(
+				&SoqlSourceSpan{Offset: asts[0].Position},
+				asts[1].Value.(SoqlFieldInfo).Span,
+			),
+		},
+	}}, nil
+}
+
+func transOperandExpressionBinaryOp(ctx ParserContext, asts AstSlice) (AstSlice, error) {
+	return AstSlice{{
+		ClassName: "soql:FieldInfo",
+		Type:      AstType_Any,
+		Value: SoqlFieldInfo{
+			Type: SoqlFieldInfo_Function,
+			Name: []string{asts[1].Value.(string)},
+			Parameters: []SoqlFieldInfo{
+				asts[0].Value.(SoqlFieldInfo),
+				asts[2].Value.(SoqlFieldInfo),
+			},
+			Span: joinSpans(
+				asts[0].Value.(SoqlFieldInfo).Span,
+				asts[2].Value.(SoqlFieldInfo).Span,
+			),
+		},
+	}}, nil
+}
+
+var operandExpressionExprRule4 = Precedence{
+	Rules: []ParserFn{
+		Trans(
+			FlatGroup(
+				isOperator("soql:ExprUnaryOp", []string{"-"}),
+				anyOperand(),
+			),
+			transOperandExpressionUnaryOp,
+		),
+	},
+	Rtol: true,
+}
+
+var operandExpressionExprRule3 = Precedence{
+	Rules: []ParserFn{
+		Trans(
+			FlatGroup(
+				anyOperand(),
+				isOperator("soql:ExprBinaryOp", []string{"*", "/"}),
+				anyOperand(),
+			),
+			transOperandExpressionBinaryOp,
+		),
+	},
+	Rtol: false,
+}
+
+var operandExpressionExprRule2 = Precedence{
+	Rules: []ParserFn{
+		Trans(
+			FlatGroup(
+				anyOperand(),
+				isOperator("soql:ExprBinaryOp", []string{"+", "-"}),
+				anyOperand(),
+			),
+			transOperandExpressionBinaryOp,
+		),
+	},
+	Rtol: false,
+}
+
+var operandExpressionExprRule1 = Precedence{
+	Rules: []ParserFn{
+		Trans(
+			FlatGroup(
+				anyOperand(),
+				isOperator("soql:ExprBinaryOp", []string{"||"}),
+				anyOperand(),
+			),
+			transOperandExpressionBinaryOp,
+		),
+	},
+	Rtol: false,
+}
+
+var operandExpressionExprProdRule TransformerFn = ProductionRule(
+	[]Precedence{
+		operandExpressionExprRule4,
+		operandExpressionExprRule3,
+		operandExpressionExprRule2,
+		operandExpressionExprRule1,
+	},
+	FlatGroup(Start(), objparser.Any(), objparser.End()),
+)
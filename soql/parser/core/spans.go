@@ -0,0 +1,246 @@
+package core
+
+import (
+	"errors"
+	"sort"
+
+	. "github.com/shellyln/go-open-soql-parser/soql/parser/types"
+	. "github.com/shellyln/takenoco/base"
+)
+
+// Returns the span without the leading and trailing whitespaces and comments.
+func trimSpan(s string, start, end int) (int, int) {
+	first, last := -1, start
+	for i := start; i < end; {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == '\v':
+			i++
+			continue
+		case c == '-' && i+1 < end && s[i+1] == '-':
+			for i < end && s[i] != '\n' && s[i] != '\r' {
+				i++
+			}
+			continue
+		case c == '/' && i+1 < end && s[i+1] == '*':
+			i += 2
+			for i < end && !(s[i] == '*' && i+1 < end && s[i+1] == '/') {
+				i++
+			}
+			i += 2
+			continue
+		}
+
+		if first < 0 {
+			first = i
+		}
+		switch c {
+		case '\'', '"', '`':
+			i = skipQuoted(s, i, c)
+		default:
+			i++
+		}
+		if i > end {
+			i = end
+		}
+		last = i
+	}
+	if first < 0 {
+		return start, start
+	}
+	return first, last
+}
+
+// Set the source position of the matched source to the resulting ASTs.
+// If the value of the AST is a node (e.g. SoqlFieldInfo), its span is also set.
+// If the value is the conditions, the span of the root condition is set.
+// Only the offsets are set; The lines and columns are set by setSpanPositions.
+func spanned(child ParserFn) ParserFn {
+	return func(ctx ParserContext) (ParserContext, error) {
+		out, err := child(ctx)
+		if err != nil || out.MatchStatus != MatchStatus_Matched {
+			return out, err
+		}
+
+		start, end := trimSpan(out.Str, ctx.Position, out.Position)
+		pos := SourcePosition{Position: start, Length: end - start}
+
+		for i := len(ctx.AstStack); i < len(out.AstStack); i++ {
+			ast := &out.AstStack[i]
+			ast.SourcePosition = pos
+
+			switch v := ast.Value.(type) {
+			case SoqlFieldInfo:
+				v.Span = spanOf(*ast)
+				ast.Value = v
+			case SoqlObjectInfo:
+				v.Span = spanOf(*ast)
+				ast.Value = v
+			case SoqlOrderByInfo:
+				v.Span = spanOf(*ast)
+				ast.Value = v
+			case SoqlDataCategoryFilter:
+				v.Span = spanOf(*ast)
+				ast.Value = v
+			case []SoqlCondition:
+				if len(v) != 0 {
+					v[len(v)-1].Span = spanOf(*ast)
+				}
+			}
+		}
+		return out, nil
+	}
+}
+
+// Error of the transformer that is reported at the source position of the AST that caused it,
+// instead of the end of the matched source.
+type positionedError struct {
+	pos SourcePosition
+	msg string
+}
+
+func (e *positionedError) Error() string {
+	return e.msg
+}
+
+// Returns the error that is reported at the source position of the AST.
+// The AST should be spanned, and the transformer should be wrapped by positioned.
+func errorAt(ast Ast, msg string) error {
+	return &positionedError{pos: ast.SourcePosition, msg: msg}
+}
+
+// Set the source position of the positionedError returned by the child to the resulting context.
+func positioned(child ParserFn) ParserFn {
+	return func(ctx ParserContext) (ParserContext, error) {
+		out, err := child(ctx)
+		var perr *positionedError
+		if errors.As(err, &perr) {
+			out.SourcePosition = perr.pos
+		}
+		return out, err
+	}
+}
+
+// Returns the span of the AST that is set by spanned.
+func spanOf(ast Ast) *SoqlSourceSpan {
+	return &SoqlSourceSpan{
+		Offset:    ast.Position,
+		EndOffset: ast.Position + ast.Length,
+	}
+}
+
+// Returns the span from the start of a to the end of b.
+// If either of them is nil, the other is returned.
+func joinSpans(a, b *SoqlSourceSpan) *SoqlSourceSpan {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	return &SoqlSourceSpan{
+		Offset:    a.Offset,
+		EndOffset: b.EndOffset,
+	}
+}
+
+// Returns the span of the root of the conditions (the last item of the RPN).
+func conditionsSpan(cond []SoqlCondition) *SoqlSourceSpan {
+	if len(cond) == 0 {
+		return nil
+	}
+	return cond[len(cond)-1].Span
+}
+
+type spanPositionResolver struct {
+	lineStarts []int
+}
+
+func newSpanPositionResolver(s string) *spanPositionResolver {
+	lineStarts := []int{0}
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\r':
+			if i+1 < len(s) && s[i+1] == '\n' {
+				i++
+			}
+			lineStarts = append(lineStarts, i+1)
+		case '\n':
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+	return &spanPositionResolver{lineStarts: lineStarts}
+}
+
+func (r *spanPositionResolver) lineAndCol(offset int) (int, int) {
+	i := sort.Search(len(r.lineStarts), func(i int) bool {
+		return r.lineStarts[i] > offset
+	}) - 1
+	return i + 1, offset - r.lineStarts[i] + 1
+}
+
+func (r *spanPositionResolver) span(span *SoqlSourceSpan) {
+	if span == nil {
+		return
+	}
+	span.Line, span.Col = r.lineAndCol(span.Offset)
+	span.EndLine, span.EndCol = r.lineAndCol(span.EndOffset)
+}
+
+func (r *spanPositionResolver) fields(fields []SoqlFieldInfo) {
+	for i := 0; i < len(fields); i++ {
+		f := &fields[i]
+		r.span(f.Span)
+		r.fields(f.Parameters)
+		for j := 0; j < len(f.Branches); j++ {
+			r.fields(f.Branches[j].Fields)
+		}
+		if f.SubQuery != nil {
+			r.query(f.SubQuery)
+		}
+	}
+}
+
+func (r *spanPositionResolver) conditions(cond []SoqlCondition) {
+	for i := 0; i < len(cond); i++ {
+		r.span(cond[i].Span)
+		if cond[i].Opcode == SoqlConditionOpcode_FieldInfo {
+			r.fields([]SoqlFieldInfo{cond[i].Value})
+		}
+	}
+}
+
+func (r *spanPositionResolver) dataCategory(filters []SoqlDataCategoryFilter) {
+	for i := 0; i < len(filters); i++ {
+		r.span(filters[i].Span)
+	}
+}
+
+func (r *spanPositionResolver) query(q *SoqlQuery) {
+	r.fields(q.Fields)
+	for i := 0; i < len(q.From); i++ {
+		r.span(q.From[i].Span)
+	}
+	r.conditions(q.Where)
+	r.fields(q.GroupBy)
+	r.conditions(q.Having)
+	for i := 0; i < len(q.OrderBy); i++ {
+		r.span(q.OrderBy[i].Span)
+		r.fields([]SoqlFieldInfo{q.OrderBy[i].Field})
+	}
+	r.dataCategory(q.With.DataCategory)
+}
+
+// Set the lines and columns of the spans in the query.
+func setSpanPositions(s string, q *SoqlQuery) {
+	newSpanPositionResolver(s).query(q)
+}
+
+// Set the lines and columns of the spans in the SOSL query.
+func setSoslSpanPositions(s string, q *SoslQuery) {
+	r := newSpanPositionResolver(s)
+	for i := 0; i < len(q.Returning); i++ {
+		r.query(&q.Returning[i])
+	}
+	r.dataCategory(q.With.DataCategory)
+}
@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"errors"
+
+	"github.com/shellyln/go-open-soql-parser/soql/parser/postprocess"
+	"github.com/shellyln/go-open-soql-parser/soql/parser/types"
+	. "github.com/shellyln/takenoco/base"
+)
+
+// Error of the Parse and ParseSosl functions.
+// Use errors.As to get the position and the error code.
+type ParseError = types.ParseError
+
+// Errors of the Parse and ParseSosl functions in the error recovery mode.
+type ParseErrorList = types.ParseErrorList
+
+func isTokenChar(c byte) bool {
+	return 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '_' || c == '$' || c >= 0x80
+}
+
+// Returns the end offset of the token that starts at the offset.
+func tokenEnd(s string, offset int) int {
+	if offset >= len(s) {
+		return len(s)
+	}
+	if !isTokenChar(s[offset]) {
+		return offset + 1
+	}
+	end := offset
+	for end < len(s) && isTokenChar(s[end]) {
+		end++
+	}
+	return end
+}
+
+func newParseError(code types.ParseErrorCode, s string, srcPos SourcePosition, message string, err error) *types.ParseError {
+	if srcPos.Position > len(s) {
+		srcPos.Position = len(s)
+	}
+	end := srcPos.Position + srcPos.Length
+	if srcPos.Length <= 0 {
+		end = tokenEnd(s, srcPos.Position)
+	}
+	if end > len(s) {
+		// The error at the end of the source (e.g. the out of range number literal at the end)
+		end = len(s)
+	}
+
+	pos := GetLineAndColPosition(s, srcPos, 4)
+	endPos := GetLineAndColPosition(s, SourcePosition{Position: end}, 4)
+
+	return &types.ParseError{
+		Code:      code,
+		Message:   message,
+		Offset:    srcPos.Position,
+		Line:      pos.Line,
+		Col:       pos.Col,
+		EndOffset: end,
+		EndLine:   endPos.Line,
+		EndCol:    endPos.Col,
+		Excerpt:   pos.ErrSource,
+		Err:       err,
+	}
+}
+
+// The span of the postprocessing error is the node that caused it.
+// If the error has no position, the span is the whole source.
+func newNormalizeError(s string, err error) *types.ParseError {
+	var nerr *postprocess.NormalizeError
+	if errors.As(err, &nerr) && nerr.Span != nil {
+		srcPos := SourcePosition{Position: nerr.Span.Offset, Length: nerr.Span.EndOffset - nerr.Span.Offset}
+		return newParseError(types.ParseErrorCode_Normalize, s, srcPos, err.Error(), err)
+	}
+
+	endPos := GetLineAndColPosition(s, SourcePosition{Position: len(s)}, 4)
+
+	return &types.ParseError{
+		Code:      types.ParseErrorCode_Normalize,
+		Message:   err.Error(),
+		Offset:    0,
+		Line:      1,
+		Col:       1,
+		EndOffset: len(s),
+		EndLine:   endPos.Line,
+		EndCol:    endPos.Col,
+		Err:       err,
+	}
+}
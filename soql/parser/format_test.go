@@ -0,0 +1,247 @@
+package parser_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/shellyln/go-open-soql-parser/soql/parser"
+)
+
+func TestFormat(t *testing.T) {
+	type args struct {
+		s string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{{
+		name: "1",
+		args: args{s: `SELECT Id, Name FROM Contact`},
+		want: `SELECT Id, Name FROM Contact`,
+	}, {
+		name: "relationship and alias",
+		args: args{s: `select con.Id, acc.Name xname, Account.Owner.Name from Contact con, con.Account acc`},
+		want: `SELECT Id, acc.Name xname, acc.Owner.Name FROM Contact con, Account acc`,
+	}, {
+		name: "functions and fieldset",
+		args: args{s: `SELECT FIELDS(standard), CONCAT(TRIM(Name), '/', 123.45, 0xff, 1.0) FROM Contact`},
+		want: `SELECT FIELDS(standard), CONCAT(TRIM(Name), '/', 123.45, 255, 1.0) FROM Contact`,
+	}, {
+		name: "float literals",
+		args: args{s: `SELECT Id FROM Contact WHERE a = 12345678901234567.89 and b = 0.000000125 and c = 1e3 and d = -2.5`},
+		want: `SELECT Id FROM Contact WHERE a = 12345678901234568.0 AND b = 0.000000125 AND c = 1000.0 AND d = -2.5`,
+	}, {
+		name: "conditions 1",
+		args: args{s: `SELECT Id FROM Contact WHERE (a = 1 and b = 2) or c = 3 and (d = 4 or e = 5)`},
+		want: `SELECT Id FROM Contact WHERE (a = 1 AND b = 2) OR (c = 3 AND (d = 4 OR e = 5))`,
+	}, {
+		name: "conditions 2",
+		args: args{s: `SELECT Id FROM Contact WHERE a = 1 and b = 2 and c = 3`},
+		want: `SELECT Id FROM Contact WHERE a = 1 AND b = 2 AND c = 3`,
+	}, {
+		name: "literals",
+		args: args{s: `SELECT Id FROM Contact WHERE a in ('x\'', 'y\n', null, true, :p1, 2023-01-02, LAST_N_DAYS:3) and b = :p2 and c > 2023-01-02T03:04:05.6Z and d < TODAY and e like 'a\_%'`},
+		want: `SELECT Id FROM Contact WHERE a IN ('x\'', 'y\n', null, true, :p1, 2023-01-02, LAST_N_DAYS:3) AND b = :p2 AND c > 2023-01-02T03:04:05.6Z AND d < TODAY AND e LIKE 'a\_%'`,
+	}, {
+		name: "subquery",
+		args: args{s: `SELECT Id, (SELECT Id FROM con.Departments WHERE Name != 'x') FROM Contact con WHERE Id not in (SELECT ContactId FROM Task)`},
+		want: `SELECT Id, (SELECT Id FROM Departments WHERE Name != 'x') FROM Contact con WHERE Id NOT IN (SELECT ContactId FROM Task)`,
+	}, {
+		name: "aggregation",
+		args: args{s: `SELECT Name, COUNT(Id) cnt FROM Contact GROUP BY Name HAVING COUNT(Id) > 1 ORDER BY Name desc nulls last LIMIT 10 OFFSET :off`},
+		want: `SELECT Name, COUNT(Id) cnt FROM Contact GROUP BY Name HAVING COUNT(Id) > 1 ORDER BY Name DESC NULLS LAST LIMIT 10 OFFSET :off`,
+	}, {
+		name: "group by rollup",
+		args: args{s: `SELECT LeadSource, GROUPING(LeadSource) grp, COUNT(Name) cnt FROM Lead group by rollup(LeadSource)`},
+		want: `SELECT LeadSource, GROUPING(LeadSource) grp, COUNT(Name) cnt FROM Lead GROUP BY ROLLUP(LeadSource)`,
+	}, {
+		name: "group by cube",
+		args: args{s: `SELECT Type, BillingCountry, COUNT(Id) cnt FROM Account GROUP BY CUBE(Type, BillingCountry)`},
+		want: `SELECT Type, BillingCountry, COUNT(Id) cnt FROM Account GROUP BY CUBE(Type, BillingCountry)`,
+	}, {
+		name: "for update",
+		args: args{s: `SELECT Id FROM Contact ORDER BY Id FOR UPDATE TRACKING`},
+		want: `SELECT Id FROM Contact ORDER BY Id FOR UPDATE TRACKING`,
+	}, {
+		name: "bind expression",
+		args: args{s: `SELECT Id FROM Contact WHERE AccountId = :acc.Id AND Name IN :someMap.keySet() AND Email = :list [0] . Email LIMIT :cfg.size`},
+		want: `SELECT Id FROM Contact WHERE AccountId = :acc.Id AND Name IN :someMap.keySet() AND Email = :list[0].Email LIMIT :cfg.size`,
+	}, {
+		name: "all rows",
+		args: args{s: `SELECT Id, (SELECT Id FROM Contacts) FROM Account acc WHERE Id IN (SELECT AccountId FROM Contact) all rows`},
+		want: `SELECT Id, (SELECT Id FROM Contacts) FROM Account acc WHERE Id IN (SELECT AccountId FROM Contact) ALL ROWS`,
+	}, {
+		name: "with",
+		args: args{s: `SELECT Id FROM Contact WHERE Name = 'a' WITH system_mode ORDER BY Id`},
+		want: `SELECT Id FROM Contact WHERE Name = 'a' WITH SYSTEM_MODE ORDER BY Id`,
+	}, {
+		name: "with data category",
+		args: args{s: `SELECT Title FROM KnowledgeArticleVersion WITH DATA CATEGORY Geography__c at (usa__c, uk__c) and Product__c above_or_below all__c with security_enforced`},
+		want: `SELECT Title FROM KnowledgeArticleVersion WITH SECURITY_ENFORCED WITH DATA CATEGORY Geography__c AT (usa__c, uk__c) AND Product__c ABOVE_OR_BELOW all__c`,
+	}, {
+		name: "using scope",
+		args: args{s: `SELECT Id, Owner.Name FROM Account acc using scope Mine, acc.Owner USING SCOPE Team WHERE Name = 'a'`},
+		want: `SELECT Id, Owner.Name FROM Account acc USING SCOPE Mine, Owner USING SCOPE Team WHERE Name = 'a'`,
+	}, {
+		name: "typeof",
+		args: args{s: `SELECT Id, typeof What when Account then Phone, NumberOfEmployees when Opportunity then Amount else Name end FROM Event WHERE What.Type = 'Account'`},
+		want: `SELECT Id, TYPEOF What WHEN Account THEN Phone, NumberOfEmployees WHEN Opportunity THEN Amount ELSE Name END FROM Event WHERE What.Type = 'Account'`,
+	}, {
+		name: "group by function",
+		args: args{s: `SELECT CALENDAR_YEAR(CreatedDate), COUNT(Id) cnt FROM Opportunity GROUP BY CALENDAR_YEAR(CreatedDate)`},
+		want: `SELECT CALENDAR_YEAR(CreatedDate), COUNT(Id) cnt FROM Opportunity GROUP BY CALENDAR_YEAR(CreatedDate)`,
+	}, {
+		name: "count without alias",
+		args: args{s: `SELECT COUNT() FROM Account`},
+		want: `SELECT COUNT() FROM Account`,
+	}, {
+		name: "functions without alias",
+		args: args{s: `SELECT CALENDAR_YEAR(CreatedDate), COUNT(Id), Name n FROM Opportunity GROUP BY CALENDAR_YEAR(CreatedDate), Name`},
+		want: `SELECT CALENDAR_YEAR(CreatedDate), COUNT(Id), Name n FROM Opportunity GROUP BY CALENDAR_YEAR(CreatedDate), Name`,
+	}, {
+		name: "expression",
+		args: args{s: `SELECT Id FROM Opportunity WHERE (Amount + 1) * 2 > a - (b - c) and -(x + y) = -(-z) and a || 'b' = 'c'`},
+		want: `SELECT Id FROM Opportunity WHERE (Amount + 1) * 2 > a - (b - c) AND -(x + y) = -(-z) AND a || 'b' = 'c'`,
+	}, {
+		name: "hints",
+		args: args{s: "SELECT Id xid `type:\"id\"  description:\"a \\\"b\\\"\"`, Name FROM Account acc `\n uri:\"account.csv\"`"},
+		want: "SELECT Id xid `type:\"id\" description:\"a \\\"b\\\"\"`, Name FROM Account acc `uri:\"account.csv\"`",
+	}, {
+		name: "datetime",
+		args: args{s: `SELECT Id FROM Event WHERE StartDateTime > 2023-01-02T03:04:05.120+09:00 AND EndDateTime < +10000-01-01T00:00Z AND Time__c IN (03:04:05.5, 23:59-05:30)`},
+		want: `SELECT Id FROM Event WHERE StartDateTime > 2023-01-02T03:04:05.120+09:00 AND EndDateTime < +10000-01-01T00:00Z AND Time__c IN (03:04:05.5, 23:59-05:30)`,
+	}, {
+		name: "currency",
+		args: args{s: `SELECT Id, "USD1" FROM Opportunity WHERE Amount > USD5000 AND ExpectedRevenue IN (EUR100.50, JPY5000)`},
+		want: `SELECT Id, "USD1" FROM Opportunity WHERE Amount > USD5000 AND ExpectedRevenue IN (EUR100.50, JPY5000)`,
+	}, {
+		name: "currency-like field name",
+		args: args{s: `SELECT Id FROM Opportunity WHERE Amount > ABC123`},
+		want: `SELECT Id FROM Opportunity WHERE Amount > ABC123`,
+	}, {
+		name: "quoted symbols",
+		args: args{s: `SELECT "select", "a b" FROM Contact`},
+		want: `SELECT "select", "a b" FROM Contact`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := parser.Parse(tt.args.s)
+			if err != nil {
+				t.Errorf("Parse() error = %v", err)
+				return
+			}
+
+			got, err := parser.Format(q)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Format() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Format() = %v, want %v", got, tt.want)
+				return
+			}
+
+			q2, err := parser.Parse(got)
+			if err != nil {
+				t.Errorf("Parse() (2) error = %v", err)
+				return
+			}
+			got2, err := parser.Format(q2)
+			if err != nil {
+				t.Errorf("Format() (2) error = %v", err)
+				return
+			}
+			if got != got2 {
+				t.Errorf("Format(1) = %v, Format(2) %v", got, got2)
+				return
+			}
+		})
+	}
+}
+
+func TestFormatSosl(t *testing.T) {
+	type args struct {
+		s string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{{
+		name: "1",
+		args: args{s: `find {acme* AND "a\}b"} in email fields returning Account(Id, Name where Name like 'a%' order by Name desc limit 5 offset 10), Contact limit :lim`},
+		want: `FIND {acme* AND "a\}b"} IN EMAIL FIELDS RETURNING Account(Id, Name WHERE Name LIKE 'a%' ORDER BY Name DESC LIMIT 5 OFFSET 10), Contact(Id) LIMIT :lim`,
+	}, {
+		name: "with and update",
+		args: args{s: `FIND 'x' RETURNING Account(Name) with highlight with division = 'Global' with network = 'n1' with data category Geography__c at usa__c with metadata = 'LABELS' with pricebookid = 'p1' update viewstat, tracking`},
+		want: `FIND {x} RETURNING Account(Name) WITH DIVISION = 'Global' WITH DATA CATEGORY Geography__c AT usa__c WITH HIGHLIGHT WITH NETWORK = 'n1' WITH PRICEBOOKID = 'p1' WITH METADATA = 'LABELS' UPDATE TRACKING, VIEWSTAT`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := parser.ParseSosl(tt.args.s)
+			if err != nil {
+				t.Errorf("ParseSosl() error = %v", err)
+				return
+			}
+
+			got, err := parser.FormatSosl(q)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("FormatSosl() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if got != tt.want {
+				t.Errorf("FormatSosl() = %v, want %v", got, tt.want)
+				return
+			}
+
+			q2, err := parser.ParseSosl(got)
+			if err != nil {
+				t.Errorf("ParseSosl() (2) error = %v", err)
+				return
+			}
+			got2, err := parser.FormatSosl(q2)
+			if err != nil {
+				t.Errorf("FormatSosl() (2) error = %v", err)
+				return
+			}
+			if got != got2 {
+				t.Errorf("FormatSosl(1) = %v, FormatSosl(2) %v", got, got2)
+				return
+			}
+		})
+	}
+}
+
+func TestFormatNonFiniteFloat(t *testing.T) {
+	tests := []struct {
+		name string
+		v    float64
+	}{
+		{name: "infinity", v: math.Inf(1)},
+		{name: "negative infinity", v: math.Inf(-1)},
+		{name: "nan", v: math.NaN()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := parser.Parse(`SELECT Id FROM Contact WHERE a = 1.5`)
+			if err != nil {
+				t.Errorf("Parse() error = %v", err)
+				return
+			}
+			q.Where[1].Value.Value = tt.v
+
+			if got, err := parser.Format(q); err == nil {
+				t.Errorf("Format() = %v, want error", got)
+			}
+		})
+	}
+}
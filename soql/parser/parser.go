@@ -2,49 +2,84 @@
 package parser
 
 import (
-	"errors"
-	"strconv"
 	"time"
 
 	"github.com/shellyln/go-open-soql-parser/soql/parser/core"
 	"github.com/shellyln/go-open-soql-parser/soql/parser/postprocess"
+	"github.com/shellyln/go-open-soql-parser/soql/parser/printer"
 	"github.com/shellyln/go-open-soql-parser/soql/parser/types"
 	. "github.com/shellyln/takenoco/base"
 	. "github.com/shellyln/takenoco/string"
 )
 
 var (
-	queryParser ParserFn
+	queryParser  ParserFn
+	searchParser ParserFn
 )
 
 func init() {
 	queryParser = core.Query()
+	searchParser = core.Search()
+}
+
+func checkParseResult(s string, out ParserContext, err error) error {
+	if err != nil {
+		return newParseError(types.ParseErrorCode_Syntax, s, out.SourcePosition, err.Error(), err)
+	}
+
+	if out.MatchStatus != MatchStatus_Matched {
+		return newParseError(types.ParseErrorCode_Unmatched, s, out.SourcePosition, "Parse failed", nil)
+	}
+
+	return nil
+}
+
+// Run the parser. In the error recovery mode, the syntax errors are collected in errs
+// and err is set only if no part of the source is parsed.
+func runParser(p ParserFn, s string, options ParseOptions) (ParserContext, types.ParseErrorList, error) {
+	opts := &core.ParserOptions{
+		DecimalLiteral: options.DecimalLiteral,
+	}
+
+	if options.Recovery {
+		out, errs := recoverParse(p, s, opts)
+		if out.MatchStatus != MatchStatus_Matched {
+			return out, errs, errs
+		}
+		return out, errs, nil
+	}
+
+	out, err := p(*NewStringParserContextWithTag(s, opts))
+	return out, nil, checkParseResult(s, out, err)
+}
+
+// Options of the Parse function.
+type ParseOptions struct {
+	// If true, the number literals that have a decimal point or an exponent (e.g. 12345678901234567.89)
+	// are parsed as Decimal (types.SoqlDecimal) to keep the exact digits.
+	// Otherwise, they are parsed as float64.
+	DecimalLiteral bool
+
+	// If true, the parser recovers from the syntax errors at the clause keywords, logical operators, commas and parentheses,
+	// and reports all of them as types.ParseErrorList.
+	// The partial query that consists of the parsed parts is returned with the errors (if any part is parsed).
+	Recovery bool
 }
 
 func Parse(s string) (*types.SoqlQuery, error) {
+	return ParseWithOptions(s, ParseOptions{})
+}
+
+func ParseWithOptions(s string, options ParseOptions) (*types.SoqlQuery, error) {
 	meta := &types.SoqlQueryMeta{
 		Version: "0.9",
 		Date:    time.Now().UTC(),
 		Source:  s,
 	}
 
-	out, err := queryParser(*NewStringParserContext(s))
+	out, errs, err := runParser(queryParser, s, options)
 	if err != nil {
-		pos := GetLineAndColPosition(s, out.SourcePosition, 4)
-		return nil, errors.New(
-			err.Error() +
-				"\n --> Line " + strconv.Itoa(pos.Line) +
-				", Col " + strconv.Itoa(pos.Col) + "\n" +
-				pos.ErrSource)
-	}
-
-	if out.MatchStatus != MatchStatus_Matched {
-		pos := GetLineAndColPosition(s, out.SourcePosition, 4)
-		return nil, errors.New(
-			"Parse failed" +
-				"\n --> Line " + strconv.Itoa(pos.Line) +
-				", Col " + strconv.Itoa(pos.Col) + "\n" +
-				pos.ErrSource)
+		return nil, err
 	}
 
 	q := out.AstStack[0].Value.(types.SoqlQuery)
@@ -52,11 +87,65 @@ func Parse(s string) (*types.SoqlQuery, error) {
 	q.Meta = meta
 
 	if err := postprocess.Normalize(&q); err != nil {
+		if !options.Recovery {
+			return nil, newNormalizeError(s, err)
+		}
+		errs = append(errs, newNormalizeError(s, err))
+	}
+
+	endDate := time.Now()
+	q.Meta.ElapsedTime = endDate.Sub(q.Meta.Date)
+
+	if len(errs) != 0 {
+		return &q, errs
+	}
+	return &q, nil
+}
+
+// Parse the SOSL (FIND statement).
+// Each object of the returning clause is parsed and normalized as an independent SOQL query.
+func ParseSosl(s string) (*types.SoslQuery, error) {
+	return ParseSoslWithOptions(s, ParseOptions{})
+}
+
+func ParseSoslWithOptions(s string, options ParseOptions) (*types.SoslQuery, error) {
+	meta := &types.SoqlQueryMeta{
+		Version: "0.9",
+		Date:    time.Now().UTC(),
+		Source:  s,
+	}
+
+	out, errs, err := runParser(searchParser, s, options)
+	if err != nil {
 		return nil, err
 	}
 
+	q := out.AstStack[0].Value.(types.SoslQuery)
+
+	q.Meta = meta
+
+	if err := postprocess.NormalizeSosl(&q); err != nil {
+		if !options.Recovery {
+			return nil, newNormalizeError(s, err)
+		}
+		errs = append(errs, newNormalizeError(s, err))
+	}
+
 	endDate := time.Now()
 	q.Meta.ElapsedTime = endDate.Sub(q.Meta.Date)
 
+	if len(errs) != 0 {
+		return &q, errs
+	}
 	return &q, nil
 }
+
+// Serialize the (normalized) query back to the SOQL text.
+func Format(q *types.SoqlQuery) (string, error) {
+	return printer.Format(q)
+}
+
+// Serialize the (normalized) SOSL query back to the SOSL text.
+func FormatSosl(q *types.SoslQuery) (string, error) {
+	return printer.FormatSosl(q)
+}
@@ -2,8 +2,11 @@ package parser_test
 
 import (
 	"encoding/json"
+	"errors"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/shellyln/go-open-soql-parser/soql/parser"
 	"github.com/shellyln/go-open-soql-parser/soql/parser/types"
@@ -103,6 +106,266 @@ func TestParse(t *testing.T) {
 		want:     nil,
 		wantErr:  false,
 		dbgBreak: true,
+	}, {
+		name:    "with 1",
+		args:    args{s: `SELECT Id FROM Contact WITH SECURITY_ENFORCED`},
+		want:    nil,
+		wantErr: false,
+	}, {
+		name:    "with 2",
+		args:    args{s: `SELECT Id FROM Contact con WHERE Name = 'a' with user_mode ORDER BY Name`},
+		want:    nil,
+		wantErr: false,
+	}, {
+		name:    "with 3",
+		args:    args{s: `SELECT Id FROM Contact WITH FOO_MODE`},
+		want:    nil,
+		wantErr: true,
+	}, {
+		name:    "with data category 1",
+		args:    args{s: `SELECT Title FROM KnowledgeArticleVersion WITH DATA CATEGORY Geography__c AT (usa__c, uk__c) AND Product__c BELOW all__c`},
+		want:    nil,
+		wantErr: false,
+	}, {
+		name:    "with data category 2",
+		args:    args{s: `SELECT Title FROM Question WHERE Title LIKE 'a%' WITH DATA CATEGORY Geography__c above_or_below europe__c WITH SECURITY_ENFORCED LIMIT 10`},
+		want:    nil,
+		wantErr: false,
+	}, {
+		name:    "with data category 3",
+		args:    args{s: `SELECT Title FROM Question WITH DATA CATEGORY Geography__c NEAR europe__c`},
+		want:    nil,
+		wantErr: true,
+	}, {
+		name:    "with data category 4",
+		args:    args{s: `SELECT Title FROM Question WITH DATA CATEGORY Geography__c AT europe__c AND geography__c BELOW asia__c`},
+		want:    nil,
+		wantErr: true,
+	}, {
+		name:    "with data category 5",
+		args:    args{s: `SELECT Title FROM Question WITH DATA CATEGORY Geography__c AT europe__c WITH DATA CATEGORY Product__c AT all__c`},
+		want:    nil,
+		wantErr: true,
+	}, {
+		name:    "using scope 1",
+		args:    args{s: `SELECT Id FROM Account USING SCOPE Mine WHERE Name = 'a'`},
+		want:    nil,
+		wantErr: false,
+	}, {
+		name:    "using scope 2",
+		args:    args{s: `SELECT Id, o.Name FROM Account acc using scope Mine_and_my_groups, acc.Owner o USING SCOPE Team`},
+		want:    nil,
+		wantErr: false,
+	}, {
+		name:    "using scope 3",
+		args:    args{s: `SELECT Id FROM Account USING Mine`},
+		want:    nil,
+		wantErr: true,
+	}, {
+		name:    "group by rollup 1",
+		args:    args{s: `SELECT LeadSource, Rating, GROUPING(LeadSource) grpLS, GROUPING(Rating) grpRating, COUNT(Name) cnt FROM Lead GROUP BY ROLLUP(LeadSource, Rating)`},
+		want:    nil,
+		wantErr: false,
+	}, {
+		name:    "group by cube 1",
+		args:    args{s: `SELECT Type, BillingCountry, GROUPING(Type) grpType, COUNT(Id) cnt FROM Account group by cube ( Type , BillingCountry ) HAVING GROUPING(Type) = 0 ORDER BY Type`},
+		want:    nil,
+		wantErr: false,
+	}, {
+		name:    "group by rollup 2",
+		args:    args{s: `SELECT Rating, GROUPING(LeadSource) grp FROM Lead GROUP BY ROLLUP(Rating)`},
+		want:    nil,
+		wantErr: true,
+	}, {
+		name:    "group by rollup 3",
+		args:    args{s: `SELECT Rating, GROUPING(Rating) grp FROM Lead GROUP BY Rating`},
+		want:    nil,
+		wantErr: true,
+	}, {
+		name:    "group by rollup 4",
+		args:    args{s: `SELECT Rating FROM Lead GROUP BY ROLLUP(Rating`},
+		want:    nil,
+		wantErr: true,
+	}, {
+		name:    "group by rollup 5",
+		args:    args{s: `SELECT Rating FROM Lead WHERE GROUPING(Rating) = 0 GROUP BY ROLLUP(Rating)`},
+		want:    nil,
+		wantErr: true,
+	}, {
+		name:    "group by function 1",
+		args:    args{s: `SELECT HOUR_IN_DAY(convertTimezone(CreatedDate)) h, SUM(Amount) FROM Opportunity GROUP BY HOUR_IN_DAY(convertTimezone(CreatedDate)) HAVING HOUR_IN_DAY(convertTimezone(CreatedDate)) > 9 ORDER BY h`},
+		want:    nil,
+		wantErr: false,
+	}, {
+		name:    "group by function 2",
+		args:    args{s: `SELECT CALENDAR_YEAR(CreatedDate) y, COUNT(Id) FROM Opportunity GROUP BY ROLLUP(y, StageName)`},
+		want:    nil,
+		wantErr: false,
+	}, {
+		name:    "group by function 3",
+		args:    args{s: `SELECT CreatedDate, COUNT(Id) FROM Opportunity GROUP BY CALENDAR_YEAR(CreatedDate)`},
+		want:    nil,
+		wantErr: true,
+	}, {
+		name:    "group by function 4",
+		args:    args{s: `SELECT COUNT(Id) FROM Opportunity GROUP BY CALENDAR_YEAR(CreatedDate), calendar_year(CreatedDate)`},
+		want:    nil,
+		wantErr: true,
+	}, {
+		name:    "group by function 5",
+		args:    args{s: `SELECT CALENDAR_YEAR(CreatedDate), COUNT(Id) FROM Account GROUP BY Name`},
+		want:    nil,
+		wantErr: true,
+	}, {
+		name:    "group by function 6",
+		args:    args{s: `SELECT CALENDAR_YEAR(CreatedDate) y, COUNT(Id) FROM Account GROUP BY CALENDAR_YEAR(CreatedDate) HAVING CALENDAR_MONTH(CreatedDate) > 1`},
+		want:    nil,
+		wantErr: true,
+	}, {
+		name:    "group by function 7",
+		args:    args{s: `SELECT HOUR_IN_DAY(convertTimezone(CreatedDate)) FROM Opportunity GROUP BY Name`},
+		want:    nil,
+		wantErr: true,
+	}, {
+		name:    "group by function 8",
+		args:    args{s: `SELECT Name, FORMAT(MIN(CloseDate)) FROM Opportunity GROUP BY Name HAVING MAX(Amount) * 2 > 10`},
+		want:    nil,
+		wantErr: false,
+	}, {
+		name:    "typeof 1",
+		args:    args{s: `SELECT Id, TYPEOF What WHEN Account THEN Phone, NumberOfEmployees WHEN Opportunity THEN Amount, CloseDate ELSE Name, Email END FROM Event WHERE What.Type IN ('Account', 'Opportunity')`},
+		want:    nil,
+		wantErr: false,
+	}, {
+		name:    "typeof 2",
+		args:    args{s: `SELECT Id, (SELECT TYPEOF Owner WHEN User THEN Username END FROM Tasks), typeof e.What when Account then Phone end FROM Event e`},
+		want:    nil,
+		wantErr: false,
+	}, {
+		name:    "typeof 3",
+		args:    args{s: `SELECT TYPEOF What WHEN Account THEN Phone WHEN account THEN Name END FROM Event`},
+		want:    nil,
+		wantErr: true,
+	}, {
+		name:    "typeof 4",
+		args:    args{s: `SELECT TYPEOF What WHEN Account THEN Phone FROM Event`},
+		want:    nil,
+		wantErr: true,
+	}, {
+		name:    "typeof 5",
+		args:    args{s: `SELECT COUNT(Id), TYPEOF What WHEN Account THEN Phone END FROM Event GROUP BY Subject`},
+		want:    nil,
+		wantErr: true,
+	}, {
+		name:    "expression 1",
+		args:    args{s: `SELECT Id FROM Opportunity WHERE Amount * 1.1 > :threshold AND FirstName || ' ' || LastName = 'A B'`},
+		want:    nil,
+		wantErr: false,
+	}, {
+		name:    "expression 2",
+		args:    args{s: `SELECT Id FROM Opportunity WHERE ((Amount + 1) * 2 > 10 OR -Amount < 0) AND (Name = 'a')`},
+		want:    nil,
+		wantErr: false,
+	}, {
+		name:    "expression 3",
+		args:    args{s: `SELECT StageName, SUM(Amount) FROM Opportunity GROUP BY StageName HAVING SUM(Amount) / COUNT(Id) > 10`},
+		want:    nil,
+		wantErr: false,
+	}, {
+		name:    "expression 4",
+		args:    args{s: `SELECT StageName, SUM(Amount) FROM Opportunity GROUP BY StageName HAVING Amount * 2 > 10`},
+		want:    nil,
+		wantErr: true,
+	}, {
+		name:    "expression 5",
+		args:    args{s: `SELECT Id FROM Opportunity WHERE Amount * > 10`},
+		want:    nil,
+		wantErr: true,
+	}, {
+		name:    "expression 6",
+		args:    args{s: `SELECT Id FROM Account WHERE 1 = Name`},
+		want:    nil,
+		wantErr: true,
+	}, {
+		name:    "expression 7",
+		args:    args{s: `SELECT Id FROM Opportunity WHERE 1 + Amount > 10 AND (-1) < Amount`},
+		want:    nil,
+		wantErr: true,
+	}, {
+		name:    "expression 8",
+		args:    args{s: `SELECT StageName, COUNT(Id) FROM Opportunity GROUP BY StageName HAVING 10 < COUNT(Id)`},
+		want:    nil,
+		wantErr: true,
+	}, {
+		name:    "hints 1",
+		args:    args{s: "SELECT (SELECT Id xid `type:\"id\" description:\"...\"`, Category `type:\"picklist\" values:\"value1;text1\"` FROM Contacts con `uri:\"contact.csv\"`, con.Foo `\n uri:\"foo.csv\"\n references:\"Foo\"`) FROM Account acc `uri:\"account.csv\"`"},
+		want:    nil,
+		wantErr: false,
+	}, {
+		name:    "hints 2",
+		args:    args{s: "SELECT Id `type:id` FROM Account"},
+		want:    nil,
+		wantErr: true,
+	}, {
+		name:    "datetime 1",
+		args:    args{s: `SELECT Id FROM Event WHERE StartDateTime > 2023-01-02T03:04:05.123+09:00 AND EndDateTime < +10000-01-01T00:00Z AND ActivityDate > -0001-01-01T00:00:00Z AND Time__c IN (03:04:05.5, 03:04Z, 23:59:59-05:30)`},
+		want:    nil,
+		wantErr: false,
+	}, {
+		name:    "datetime 2",
+		args:    args{s: `SELECT Id FROM Event WHERE StartDateTime > 2023-02-29T03:04:05Z`},
+		want:    nil,
+		wantErr: true,
+	}, {
+		name:    "datetime 3",
+		args:    args{s: `SELECT Id FROM Event WHERE StartDateTime > 2023-01-02T03:04:05`},
+		want:    nil,
+		wantErr: true,
+	}, {
+		name:    "currency 1",
+		args:    args{s: `SELECT Id FROM Opportunity WHERE Amount > USD5000 AND ExpectedRevenue IN (EUR100.50, JPY5000)`},
+		want:    nil,
+		wantErr: false,
+	}, {
+		name:    "currency 2",
+		args:    args{s: `SELECT Name, MAX(Amount) FROM Opportunity GROUP BY Name HAVING MAX(Amount) > USD10000`},
+		want:    nil,
+		wantErr: false,
+	}, {
+		name:    "bind expression 1",
+		args:    args{s: `SELECT Id FROM Contact WHERE AccountId = :acc.Id AND Id IN :ids AND Name IN :someMap.keySet() AND Email = :list[0].Email LIMIT :cfg.size`},
+		want:    nil,
+		wantErr: false,
+	}, {
+		name:    "bind expression 2",
+		args:    args{s: `SELECT Id FROM Contact WHERE Id = :m [ i . j ] . get ( )`},
+		want:    nil,
+		wantErr: false,
+	}, {
+		name:    "bind expression 3",
+		args:    args{s: `SELECT Id FROM Contact WHERE Id = :m.get(1)`},
+		want:    nil,
+		wantErr: true,
+	}, {
+		name:    "all rows 1",
+		args:    args{s: `SELECT Id, (SELECT Id FROM Contacts) FROM Account WHERE IsDeleted = true all rows`},
+		want:    nil,
+		wantErr: false,
+	}, {
+		name:    "all rows 2",
+		args:    args{s: `SELECT Id FROM Account ALL ROWS`},
+		want:    nil,
+		wantErr: false,
+	}, {
+		name:    "all rows 3",
+		args:    args{s: `SELECT Id, (SELECT Id FROM Contacts ALL ROWS) FROM Account`},
+		want:    nil,
+		wantErr: true,
+	}, {
+		name:    "for 1",
+		args:    args{s: `SELECT Id FROM Contact FOR VIEW`},
+		want:    nil,
+		wantErr: false,
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -427,3 +690,1147 @@ func TestParse3(t *testing.T) {
 		})
 	}
 }
+
+func TestConditionalOperator(t *testing.T) {
+	type args struct {
+		s string
+	}
+	tests := []struct {
+		name string
+		args args
+		want types.SoqlConditionOpcode
+	}{{
+		name: "in",
+		args: args{s: `SELECT Id FROM Contact WHERE Name in ('a', 'b')`},
+		want: types.SoqlConditionOpcode_In,
+	}, {
+		name: "not in",
+		args: args{s: `SELECT Id FROM Contact WHERE Name not in ('a', 'b')`},
+		want: types.SoqlConditionOpcode_NotIn,
+	}, {
+		name: "not in (uppercase and spaces)",
+		args: args{s: `SELECT Id FROM Contact WHERE Name NOT   IN ('a', 'b')`},
+		want: types.SoqlConditionOpcode_NotIn,
+	}, {
+		name: "like",
+		args: args{s: `SELECT Id FROM Contact WHERE Name like 'a%'`},
+		want: types.SoqlConditionOpcode_Like,
+	}, {
+		name: "not like",
+		args: args{s: `SELECT Id FROM Contact WHERE Name Not Like 'a%'`},
+		want: types.SoqlConditionOpcode_NotLike,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parser.Parse(tt.args.s)
+			if err != nil {
+				t.Errorf("Parse() error = %v", err)
+				return
+			}
+			if n := len(got.Where); n == 0 || got.Where[n-1].Opcode != tt.want {
+				t.Errorf("Parse() where = %v, want opcode %v", got.Where, tt.want)
+			}
+		})
+	}
+}
+
+func TestCurrencyLiteral(t *testing.T) {
+	type args struct {
+		s string
+	}
+	tests := []struct {
+		name  string
+		args  args
+		want  types.SoqlFieldInfoType
+		value interface{}
+		codes map[string]struct{}
+	}{{
+		name:  "currency",
+		args:  args{s: `SELECT Id FROM Opportunity WHERE Amount > USD5000`},
+		want:  types.SoqlFieldInfo_Literal_Currency,
+		value: types.SoqlCurrency{Code: "USD", Value: types.SoqlDecimal("5000")},
+		codes: map[string]struct{}{"USD": {}},
+	}, {
+		name:  "currency decimal",
+		args:  args{s: `SELECT Id FROM Opportunity WHERE Amount > EUR100.50`},
+		want:  types.SoqlFieldInfo_Literal_Currency,
+		value: types.SoqlCurrency{Code: "EUR", Value: types.SoqlDecimal("100.50")},
+		codes: map[string]struct{}{"EUR": {}},
+	}, {
+		name:  "unknown code",
+		args:  args{s: `SELECT Id FROM Opportunity WHERE Amount > ABC123`},
+		want:  types.SoqlFieldInfo_Field,
+		codes: map[string]struct{}{},
+	}, {
+		name:  "lowercase code",
+		args:  args{s: `SELECT Id FROM Opportunity WHERE Amount > usd5000`},
+		want:  types.SoqlFieldInfo_Field,
+		codes: map[string]struct{}{},
+	}, {
+		name:  "no word boundary",
+		args:  args{s: `SELECT Id FROM Opportunity WHERE Amount > USD5000x`},
+		want:  types.SoqlFieldInfo_Field,
+		codes: map[string]struct{}{},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parser.Parse(tt.args.s)
+			if err != nil {
+				t.Errorf("Parse() error = %v", err)
+				return
+			}
+			if len(got.Where) != 3 || got.Where[1].Value.Type != tt.want {
+				t.Errorf("Parse() where = %v, want operand type %v", got.Where, tt.want)
+				return
+			}
+			if !reflect.DeepEqual(got.Where[1].Value.Value, tt.value) {
+				t.Errorf("Parse() value = %v, want %v", got.Where[1].Value.Value, tt.value)
+			}
+			if !reflect.DeepEqual(got.Meta.CurrencyCodes, tt.codes) {
+				t.Errorf("Parse() currency codes = %v, want %v", got.Meta.CurrencyCodes, tt.codes)
+			}
+		})
+	}
+}
+
+func TestWithClause(t *testing.T) {
+	type args struct {
+		s string
+	}
+	tests := []struct {
+		name string
+		args args
+		want types.SoqlWithClause
+	}{{
+		name: "security enforced",
+		args: args{s: `SELECT Id FROM Contact WITH SECURITY_ENFORCED`},
+		want: types.SoqlWithClause{SecurityEnforced: true},
+	}, {
+		name: "user mode",
+		args: args{s: `SELECT Id FROM Contact con WHERE Name = 'a' with user_mode ORDER BY Name`},
+		want: types.SoqlWithClause{UserMode: true},
+	}, {
+		name: "system mode",
+		args: args{s: `SELECT Id FROM Contact WITH SYSTEM_MODE LIMIT 10`},
+		want: types.SoqlWithClause{SystemMode: true},
+	}, {
+		name: "none",
+		args: args{s: `SELECT Id FROM Contact`},
+		want: types.SoqlWithClause{},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parser.Parse(tt.args.s)
+			if err != nil {
+				t.Errorf("Parse() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got.With, tt.want) {
+				t.Errorf("Parse() with = %+v, want %+v", got.With, tt.want)
+			}
+		})
+	}
+}
+
+func TestDataCategoryFilter(t *testing.T) {
+	type args struct {
+		s string
+	}
+	type want struct {
+		filters []types.SoqlDataCategoryFilter
+		groups  map[string]struct{}
+	}
+	tests := []struct {
+		name string
+		args args
+		want want
+	}{{
+		name: "multiple filters",
+		args: args{s: `SELECT Title FROM KnowledgeArticleVersion WITH DATA CATEGORY Geography__c AT (usa__c, uk__c) AND Product__c BELOW all__c`},
+		want: want{
+			filters: []types.SoqlDataCategoryFilter{{
+				GroupName:  "Geography__c",
+				Operator:   types.SoqlDataCategoryOperator_At,
+				Categories: []string{"usa__c", "uk__c"},
+			}, {
+				GroupName:  "Product__c",
+				Operator:   types.SoqlDataCategoryOperator_Below,
+				Categories: []string{"all__c"},
+			}},
+			groups: map[string]struct{}{"geography__c": {}, "product__c": {}},
+		},
+	}, {
+		name: "with security enforced",
+		args: args{s: `SELECT Title FROM Question WHERE Title LIKE 'a%' WITH DATA CATEGORY Geography__c above_or_below europe__c WITH SECURITY_ENFORCED LIMIT 10`},
+		want: want{
+			filters: []types.SoqlDataCategoryFilter{{
+				GroupName:  "Geography__c",
+				Operator:   types.SoqlDataCategoryOperator_AboveOrBelow,
+				Categories: []string{"europe__c"},
+			}},
+			groups: map[string]struct{}{"geography__c": {}},
+		},
+	}, {
+		name: "above",
+		args: args{s: `SELECT Title FROM Question WITH DATA CATEGORY Geography__c ABOVE europe__c`},
+		want: want{
+			filters: []types.SoqlDataCategoryFilter{{
+				GroupName:  "Geography__c",
+				Operator:   types.SoqlDataCategoryOperator_Above,
+				Categories: []string{"europe__c"},
+			}},
+			groups: map[string]struct{}{"geography__c": {}},
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parser.Parse(tt.args.s)
+			if err != nil {
+				t.Errorf("Parse() error = %v", err)
+				return
+			}
+
+			filters := make([]types.SoqlDataCategoryFilter, len(got.With.DataCategory))
+			for i, f := range got.With.DataCategory {
+				if f.Span == nil {
+					t.Errorf("Parse() data category[%d] has no span", i)
+				}
+				f.Span = nil
+				filters[i] = f
+			}
+			if !reflect.DeepEqual(filters, tt.want.filters) {
+				t.Errorf("Parse() data category = %+v, want %+v", filters, tt.want.filters)
+			}
+			if !reflect.DeepEqual(got.Meta.DataCategoryGroups, tt.want.groups) {
+				t.Errorf("Parse() data category groups = %v, want %v", got.Meta.DataCategoryGroups, tt.want.groups)
+			}
+		})
+	}
+}
+
+func TestUsingScope(t *testing.T) {
+	type args struct {
+		s string
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string // using scope of each object of the from clause
+	}{{
+		name: "single object",
+		args: args{s: `SELECT Id FROM Account USING SCOPE Mine WHERE Name = 'a'`},
+		want: []string{"Mine"},
+	}, {
+		name: "multiple objects",
+		args: args{s: `SELECT Id, o.Name FROM Account acc using scope Mine_and_my_groups, acc.Owner o USING SCOPE Team`},
+		want: []string{"Mine_and_my_groups", "Team"},
+	}, {
+		name: "none",
+		args: args{s: `SELECT Id FROM Account`},
+		want: []string{""},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parser.Parse(tt.args.s)
+			if err != nil {
+				t.Errorf("Parse() error = %v", err)
+				return
+			}
+
+			scopes := make([]string, len(got.From))
+			for i, object := range got.From {
+				scopes[i] = object.UsingScope
+			}
+			if !reflect.DeepEqual(scopes, tt.want) {
+				t.Errorf("Parse() using scope = %v, want %v", scopes, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupingMode(t *testing.T) {
+	type args struct {
+		s string
+	}
+	type want struct {
+		mode    types.SoqlGroupingMode
+		groupBy []string // group by field names
+	}
+	tests := []struct {
+		name string
+		args args
+		want want
+	}{{
+		name: "rollup",
+		args: args{s: `SELECT LeadSource, Rating, GROUPING(LeadSource) grpLS, GROUPING(Rating) grpRating, COUNT(Name) cnt FROM Lead GROUP BY ROLLUP(LeadSource, Rating)`},
+		want: want{
+			mode:    types.SoqlGroupingMode_Rollup,
+			groupBy: []string{"Lead.LeadSource", "Lead.Rating"},
+		},
+	}, {
+		name: "cube",
+		args: args{s: `SELECT Type, BillingCountry, GROUPING(Type) grpType, COUNT(Id) cnt FROM Account group by cube ( Type , BillingCountry ) HAVING GROUPING(Type) = 0 ORDER BY Type`},
+		want: want{
+			mode:    types.SoqlGroupingMode_Cube,
+			groupBy: []string{"Account.Type", "Account.BillingCountry"},
+		},
+	}, {
+		name: "default",
+		args: args{s: `SELECT Rating, COUNT(Id) cnt FROM Lead GROUP BY Rating`},
+		want: want{
+			mode:    types.SoqlGroupingMode_Default,
+			groupBy: []string{"Lead.Rating"},
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parser.Parse(tt.args.s)
+			if err != nil {
+				t.Errorf("Parse() error = %v", err)
+				return
+			}
+			if got.GroupingMode != tt.want.mode {
+				t.Errorf("Parse() grouping mode = %v, want %v", got.GroupingMode, tt.want.mode)
+			}
+
+			groupBy := make([]string, len(got.GroupBy))
+			for i, field := range got.GroupBy {
+				groupBy[i] = strings.Join(field.Name, ".")
+			}
+			if !reflect.DeepEqual(groupBy, tt.want.groupBy) {
+				t.Errorf("Parse() group by = %v, want %v", groupBy, tt.want.groupBy)
+			}
+		})
+	}
+}
+
+func TestHints(t *testing.T) {
+	type args struct {
+		s string
+	}
+	type want struct {
+		fields  [][]types.SoqlQueryHint // hints of each field of the select clause
+		objects [][]types.SoqlQueryHint // hints of each object of the from clause
+	}
+	tests := []struct {
+		name string
+		args args
+		want want
+	}{{
+		name: "field and object",
+		args: args{s: "SELECT Id `type:\"id\"` FROM Account `uri:\"account.csv\"`"},
+		want: want{
+			fields:  [][]types.SoqlQueryHint{{{Name: "type", Value: "id"}}},
+			objects: [][]types.SoqlQueryHint{{{Name: "uri", Value: "account.csv"}}},
+		},
+	}, {
+		name: "multiple hints",
+		args: args{s: "SELECT Id xid `type:\"id\" description:\"...\"`, Category `type:\"picklist\" values:\"value1;text1\"` FROM Contact con `\n uri:\"contact.csv\"\n references:\"Foo\"`"},
+		want: want{
+			fields: [][]types.SoqlQueryHint{
+				{{Name: "type", Value: "id"}, {Name: "description", Value: "..."}},
+				{{Name: "type", Value: "picklist"}, {Name: "values", Value: "value1;text1"}},
+			},
+			objects: [][]types.SoqlQueryHint{{{Name: "uri", Value: "contact.csv"}, {Name: "references", Value: "Foo"}}},
+		},
+	}, {
+		name: "none",
+		args: args{s: "SELECT Id FROM Account"},
+		want: want{
+			fields:  [][]types.SoqlQueryHint{nil},
+			objects: [][]types.SoqlQueryHint{nil},
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parser.Parse(tt.args.s)
+			if err != nil {
+				t.Errorf("Parse() error = %v", err)
+				return
+			}
+
+			fields := make([][]types.SoqlQueryHint, len(got.Fields))
+			for i, field := range got.Fields {
+				fields[i] = field.Hints
+			}
+			if !reflect.DeepEqual(fields, tt.want.fields) {
+				t.Errorf("Parse() field hints = %v, want %v", fields, tt.want.fields)
+			}
+
+			objects := make([][]types.SoqlQueryHint, len(got.From))
+			for i, object := range got.From {
+				objects[i] = object.Hints
+			}
+			if !reflect.DeepEqual(objects, tt.want.objects) {
+				t.Errorf("Parse() object hints = %v, want %v", objects, tt.want.objects)
+			}
+		})
+	}
+}
+
+func TestDateTimeLiteral(t *testing.T) {
+	type args struct {
+		s string
+	}
+	tests := []struct {
+		name string
+		args args
+		want types.SoqlDateTimeValue
+	}{{
+		name: "datetime with offset",
+		args: args{s: `SELECT Id FROM Event WHERE StartDateTime > 2023-01-02T03:04:05.123+09:00`},
+		want: types.SoqlDateTimeValue{
+			Time:      time.Date(2023, 1, 2, 3, 4, 5, 123000000, time.FixedZone("", 9*60*60)),
+			HasOffset: true,
+			Source:    "2023-01-02T03:04:05.123+09:00",
+		},
+	}, {
+		name: "datetime after year 9999",
+		args: args{s: `SELECT Id FROM Event WHERE EndDateTime < +10000-01-01T00:00Z`},
+		want: types.SoqlDateTimeValue{
+			Time:      time.Date(10000, 1, 1, 0, 0, 0, 0, time.UTC),
+			HasOffset: true,
+			Source:    "+10000-01-01T00:00Z",
+		},
+	}, {
+		name: "datetime before year 0",
+		args: args{s: `SELECT Id FROM Event WHERE ActivityDate > -0001-01-01T00:00:00Z`},
+		want: types.SoqlDateTimeValue{
+			Time:      time.Date(-1, 1, 1, 0, 0, 0, 0, time.UTC),
+			HasOffset: true,
+			Source:    "-0001-01-01T00:00:00Z",
+		},
+	}, {
+		name: "time without offset",
+		args: args{s: `SELECT Id FROM Event WHERE Time__c = 03:04:05.5`},
+		want: types.SoqlDateTimeValue{
+			Time:      time.Date(1970, 1, 1, 3, 4, 5, 500000000, time.UTC),
+			HasOffset: false,
+			Source:    "03:04:05.5",
+		},
+	}, {
+		name: "time with offset",
+		args: args{s: `SELECT Id FROM Event WHERE Time__c = 23:59:59-05:30`},
+		want: types.SoqlDateTimeValue{
+			Time:      time.Date(1970, 1, 1, 23, 59, 59, 0, time.FixedZone("", -(5*60+30)*60)),
+			HasOffset: true,
+			Source:    "23:59:59-05:30",
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parser.Parse(tt.args.s)
+			if err != nil {
+				t.Errorf("Parse() error = %v", err)
+				return
+			}
+
+			v, ok := got.Where[1].Value.Value.(types.SoqlDateTimeValue)
+			if !ok {
+				t.Errorf("Parse() = %v, want %v", got.Where[1].Value.Value, tt.want)
+				return
+			}
+			_, offset := v.Time.Zone()
+			_, wantOffset := tt.want.Time.Zone()
+			if !v.Time.Equal(tt.want.Time) || offset != wantOffset {
+				t.Errorf("Parse() time = %v, want %v", v.Time, tt.want.Time)
+			}
+			if v.HasOffset != tt.want.HasOffset {
+				t.Errorf("Parse() has offset = %v, want %v", v.HasOffset, tt.want.HasOffset)
+			}
+			if v.Source != tt.want.Source {
+				t.Errorf("Parse() source = %v, want %v", v.Source, tt.want.Source)
+			}
+		})
+	}
+}
+
+func TestAllRows(t *testing.T) {
+	type args struct {
+		s string
+	}
+	type want struct {
+		allRows    bool
+		subQueries []bool // all rows of each subquery of the select clause
+		perObjects []bool // all rows of the per-object query of each object (including the objects of the subqueries)
+	}
+	tests := []struct {
+		name string
+		args args
+		want want
+	}{{
+		name: "with subquery",
+		args: args{s: `SELECT Id, (SELECT Id FROM Contacts) FROM Account WHERE IsDeleted = true all rows`},
+		want: want{
+			allRows:    true,
+			subQueries: []bool{true},
+			perObjects: []bool{true, true},
+		},
+	}, {
+		name: "with parent object",
+		args: args{s: `SELECT Id, Owner.Name FROM Account ALL ROWS`},
+		want: want{
+			allRows:    true,
+			subQueries: []bool{},
+			perObjects: []bool{true, true},
+		},
+	}, {
+		name: "none",
+		args: args{s: `SELECT Id, (SELECT Id FROM Contacts) FROM Account`},
+		want: want{
+			allRows:    false,
+			subQueries: []bool{false},
+			perObjects: []bool{false, false},
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parser.Parse(tt.args.s)
+			if err != nil {
+				t.Errorf("Parse() error = %v", err)
+				return
+			}
+			if got.AllRows != tt.want.allRows {
+				t.Errorf("Parse() all rows = %v, want %v", got.AllRows, tt.want.allRows)
+			}
+
+			subQueries := []bool{}
+			perObjects := []bool{}
+			for _, object := range got.From {
+				perObjects = append(perObjects, object.PerObjectQuery != nil && object.PerObjectQuery.AllRows)
+			}
+			for _, field := range got.Fields {
+				if field.SubQuery == nil {
+					continue
+				}
+				subQueries = append(subQueries, field.SubQuery.AllRows)
+				for _, object := range field.SubQuery.From {
+					perObjects = append(perObjects, object.PerObjectQuery != nil && object.PerObjectQuery.AllRows)
+				}
+			}
+			if !reflect.DeepEqual(subQueries, tt.want.subQueries) {
+				t.Errorf("Parse() all rows of subqueries = %v, want %v", subQueries, tt.want.subQueries)
+			}
+			if !reflect.DeepEqual(perObjects, tt.want.perObjects) {
+				t.Errorf("Parse() all rows of per-object queries = %v, want %v", perObjects, tt.want.perObjects)
+			}
+		})
+	}
+}
+
+func TestBindExpression(t *testing.T) {
+	type args struct {
+		s string
+	}
+	type want struct {
+		params    []string // bind expressions of the where clause
+		limit     string   // bind expression of the limit clause
+		paramKeys map[string]struct{}
+	}
+	tests := []struct {
+		name string
+		args args
+		want want
+	}{{
+		name: "member access and method call",
+		args: args{s: `SELECT Id FROM Contact WHERE AccountId = :acc.Id AND Id IN :ids AND Name IN :someMap.keySet() AND Email = :list[0].Email LIMIT :cfg.size`},
+		want: want{
+			params: []string{"acc.Id", "ids", "someMap.keySet()", "list[0].Email"},
+			limit:  "cfg.size",
+			paramKeys: map[string]struct{}{
+				"acc": {}, "ids": {}, "somemap": {}, "list": {}, "cfg": {},
+			},
+		},
+	}, {
+		name: "whitespaces",
+		args: args{s: `SELECT Id FROM Contact WHERE Id = :m [ i . j ] . get ( )`},
+		want: want{
+			params:    []string{"m[i.j].get()"},
+			paramKeys: map[string]struct{}{"m": {}},
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parser.Parse(tt.args.s)
+			if err != nil {
+				t.Errorf("Parse() error = %v", err)
+				return
+			}
+
+			params := []string{}
+			for _, cond := range got.Where {
+				if cond.Opcode == types.SoqlConditionOpcode_FieldInfo && cond.Value.Type == types.SoqlFieldInfo_ParameterizedValue {
+					params = append(params, strings.Join(cond.Value.Name, "."))
+				}
+			}
+			if !reflect.DeepEqual(params, tt.want.params) {
+				t.Errorf("Parse() parameters = %v, want %v", params, tt.want.params)
+			}
+			if got.OffsetAndLimit.LimitParamName != tt.want.limit {
+				t.Errorf("Parse() limit parameter = %v, want %v", got.OffsetAndLimit.LimitParamName, tt.want.limit)
+			}
+			if !reflect.DeepEqual(got.Meta.Parameters, tt.want.paramKeys) {
+				t.Errorf("Parse() meta parameters = %v, want %v", got.Meta.Parameters, tt.want.paramKeys)
+			}
+		})
+	}
+}
+
+func TestParseWithOptions(t *testing.T) {
+	type args struct {
+		s       string
+		options parser.ParseOptions
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    interface{}
+		wantErr bool
+	}{{
+		name: "float literal",
+		args: args{
+			s:       `SELECT Id FROM Opportunity WHERE Amount = 12345678901234567.89`,
+			options: parser.ParseOptions{},
+		},
+		want:    float64(12345678901234567.89),
+		wantErr: false,
+	}, {
+		name: "decimal literal",
+		args: args{
+			s:       `SELECT Id FROM Opportunity WHERE Amount = 12345678901234567.89`,
+			options: parser.ParseOptions{DecimalLiteral: true},
+		},
+		want:    types.SoqlDecimal("12345678901234567.89"),
+		wantErr: false,
+	}, {
+		name: "decimal literal with exponent",
+		args: args{
+			s:       `SELECT Id FROM Opportunity WHERE Amount = 1.5e3`,
+			options: parser.ParseOptions{DecimalLiteral: true},
+		},
+		want:    types.SoqlDecimal("1.5e3"),
+		wantErr: false,
+	}, {
+		name: "negative decimal literal",
+		args: args{
+			s:       `SELECT Id FROM Opportunity WHERE Amount = -0.10`,
+			options: parser.ParseOptions{DecimalLiteral: true},
+		},
+		want:    types.SoqlDecimal("-0.10"),
+		wantErr: false,
+	}, {
+		name: "decimal literal with plus sign",
+		args: args{
+			s:       `SELECT Id FROM Opportunity WHERE Amount = +2.50`,
+			options: parser.ParseOptions{DecimalLiteral: true},
+		},
+		want:    types.SoqlDecimal("2.50"),
+		wantErr: false,
+	}, {
+		name: "decimal literal in list",
+		args: args{
+			s:       `SELECT Id FROM Opportunity WHERE Amount IN (1.50, 3)`,
+			options: parser.ParseOptions{DecimalLiteral: true},
+		},
+		want: []types.SoqlListItem{
+			{Type: types.SoqlFieldInfo_Literal_Decimal, Value: types.SoqlDecimal("1.50")},
+			{Type: types.SoqlFieldInfo_Literal_Int, Value: int64(3)},
+		},
+		wantErr: false,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parser.ParseWithOptions(tt.args.s, tt.args.options)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseWithOptions() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+
+			if !reflect.DeepEqual(got.Where[1].Value.Value, tt.want) {
+				t.Errorf("ParseWithOptions() = %v, want %v", got.Where[1].Value.Value, tt.want)
+				return
+			}
+
+			jsonBytes, err := json.Marshal(got)
+			if err != nil {
+				t.Errorf("json.Marshal() error = %v", err)
+				return
+			}
+			var unmarshal types.SoqlQuery
+			err = json.Unmarshal(jsonBytes, &unmarshal)
+			if err != nil {
+				t.Errorf("json.Unmarshal() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(unmarshal.Where[1].Value.Value, tt.want) {
+				t.Errorf("json.Unmarshal() = %v, want %v", unmarshal.Where[1].Value.Value, tt.want)
+				return
+			}
+		})
+	}
+}
+
+func TestParseSosl(t *testing.T) {
+	type args struct {
+		s string
+	}
+	type want struct {
+		searchQuery string
+		searchGroup types.SoslSearchGroup
+		returning   []string // object name (field names...)
+		with        types.SoslWithClause
+		limit       int64
+		update      types.SoslUpdateClause
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    want
+		wantErr bool
+	}{{
+		name: "1",
+		args: args{s: `FIND {acme*} IN NAME FIELDS RETURNING Account(Id, Name WHERE Name LIKE 'a%' ORDER BY Name LIMIT 5), Contact(Id) LIMIT 20`},
+		want: want{
+			searchQuery: `acme*`,
+			searchGroup: types.SoslSearchGroup_NameFields,
+			returning:   []string{"Account(Id,Name)", "Contact(Id)"},
+			limit:       20,
+		},
+	}, {
+		name: "2",
+		args: args{s: `find 'foo bar' returning Account, Contact with division = 'Global' with snippet (target_length = 120) with network in ('a', 'b') update tracking`},
+		want: want{
+			searchQuery: `foo bar`,
+			searchGroup: types.SoslSearchGroup_AllFields,
+			returning:   []string{"Account(Id)", "Contact(Id)"},
+			with: types.SoslWithClause{
+				Division:            "Global",
+				Snippet:             true,
+				SnippetTargetLength: 120,
+				Network:             []string{"a", "b"},
+			},
+			update: types.SoslUpdateClause{Tracking: true},
+		},
+	}, {
+		name: "3",
+		args: args{s: `FIND {a\}b\\} WITH SECURITY_ENFORCED WITH SPELL_CORRECTION = false`},
+		want: want{
+			searchQuery: `a\}b\\`,
+			with: types.SoslWithClause{
+				SoqlWithClause:    types.SoqlWithClause{SecurityEnforced: true},
+				NoSpellCorrection: true,
+			},
+		},
+	}, {
+		name:    "duplicate object",
+		args:    args{s: `FIND {a} RETURNING Account(Name), account`},
+		wantErr: true,
+	}, {
+		name:    "unterminated search query",
+		args:    args{s: `FIND {a RETURNING Account`},
+		wantErr: true,
+	}, {
+		name:    "unknown with clause",
+		args:    args{s: `FIND {a} WITH foo`},
+		wantErr: true,
+	}, {
+		name:    "trailing token",
+		args:    args{s: `FIND {a} RETURNING Account(Name) foo`},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parser.ParseSosl(tt.args.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseSosl() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+
+			returning := make([]string, len(got.Returning))
+			for i, q := range got.Returning {
+				if q.Meta == nil || q.Meta.Source != tt.args.s {
+					t.Errorf("ParseSosl() returning[%d] is not normalized", i)
+					return
+				}
+				fields := make([]string, len(q.Fields))
+				for j, f := range q.Fields {
+					fields[j] = strings.Join(f.Name[1:], ".")
+				}
+				returning[i] = strings.Join(q.From[0].Name, ".") + "(" + strings.Join(fields, ",") + ")"
+			}
+			if len(returning) == 0 {
+				returning = nil
+			}
+
+			if got.SearchQuery != tt.want.searchQuery {
+				t.Errorf("ParseSosl() searchQuery = %v, want %v", got.SearchQuery, tt.want.searchQuery)
+			}
+			if got.SearchGroup != tt.want.searchGroup {
+				t.Errorf("ParseSosl() searchGroup = %v, want %v", got.SearchGroup, tt.want.searchGroup)
+			}
+			if !reflect.DeepEqual(returning, tt.want.returning) {
+				t.Errorf("ParseSosl() returning = %v, want %v", returning, tt.want.returning)
+			}
+			if !reflect.DeepEqual(got.With, tt.want.with) {
+				t.Errorf("ParseSosl() with = %v, want %v", got.With, tt.want.with)
+			}
+			if got.Limit != tt.want.limit {
+				t.Errorf("ParseSosl() limit = %v, want %v", got.Limit, tt.want.limit)
+			}
+			if got.Update != tt.want.update {
+				t.Errorf("ParseSosl() update = %v, want %v", got.Update, tt.want.update)
+			}
+		})
+	}
+}
+
+func TestParseError(t *testing.T) {
+	type args struct {
+		s string
+	}
+	tests := []struct {
+		name string
+		args args
+		want parser.ParseError
+	}{{
+		name: "syntax",
+		args: args{s: "SELECT Id\nFROM Account\nWHERE Name = ORDER BY Id"},
+		want: parser.ParseError{
+			Code:      types.ParseErrorCode_Syntax,
+			Message:   "Unexpected token aheads near by the 'where' clause (unknown operand2)",
+			Offset:    36,
+			Line:      3,
+			Col:       14,
+			EndOffset: 41,
+			EndLine:   3,
+			EndCol:    19,
+		},
+	}, {
+		name: "unterminated",
+		args: args{s: "FIND {a} RETURNING Account("},
+		want: parser.ParseError{
+			Code: types.ParseErrorCode_Syntax,
+		},
+	}, {
+		name: "end of source",
+		args: args{s: "SELECT Id FROM Contact\nWHERE a = 1e999"},
+		want: parser.ParseError{
+			Code:      types.ParseErrorCode_Syntax,
+			Message:   `Transformer:ParseFloat:Bad number format:1e999:strconv.ParseFloat: parsing "1e999": value out of range`,
+			Offset:    38,
+			Line:      2,
+			Col:       16,
+			EndOffset: 38,
+			EndLine:   2,
+			EndCol:    16,
+		},
+	}, {
+		name: "normalize",
+		args: args{s: "SELECT Name FROM Account\nGROUP BY Name, name"},
+		want: parser.ParseError{
+			Code:      types.ParseErrorCode_Normalize,
+			Message:   "Duplicate field found in Group by clause: Account.name",
+			Offset:    40,
+			Line:      2,
+			Col:       16,
+			EndOffset: 44,
+			EndLine:   2,
+			EndCol:    20,
+		},
+	}, {
+		name: "normalize duplicate alias",
+		args: args{s: "SELECT Id\nFROM Contact c, c.Account c"},
+		want: parser.ParseError{
+			Code:      types.ParseErrorCode_Normalize,
+			Message:   "Duplicate object alias name found: c",
+			Offset:    26,
+			Line:      2,
+			Col:       17,
+			EndOffset: 37,
+			EndLine:   2,
+			EndCol:    28,
+		},
+	}, {
+		name: "normalize ungrouped field",
+		args: args{s: "SELECT Name,\n  Id\nFROM Account GROUP BY Name"},
+		want: parser.ParseError{
+			Code:      types.ParseErrorCode_Normalize,
+			Message:   "The item must be included in a Group By clause: Account.Id",
+			Offset:    15,
+			Line:      2,
+			Col:       3,
+			EndOffset: 17,
+			EndLine:   2,
+			EndCol:    5,
+		},
+	}, {
+		name: "normalize ungrouped function",
+		args: args{s: "SELECT Name,\n  CALENDAR_YEAR(CreatedDate)\nFROM Account GROUP BY Name"},
+		want: parser.ParseError{
+			Code:      types.ParseErrorCode_Normalize,
+			Message:   "The function must be an aggregate function or included in a Group By clause: CALENDAR_YEAR",
+			Offset:    15,
+			Line:      2,
+			Col:       3,
+			EndOffset: 41,
+			EndLine:   2,
+			EndCol:    29,
+		},
+	}, {
+		name: "literal left operand",
+		args: args{s: "SELECT Id FROM Account\nWHERE Name = 'a' AND 1 = Name"},
+		want: parser.ParseError{
+			Code:      types.ParseErrorCode_Syntax,
+			Message:   "The left-hand side of the conditional expression must be a field or an expression",
+			Offset:    44,
+			Line:      2,
+			Col:       22,
+			EndOffset: 45,
+			EndLine:   2,
+			EndCol:    23,
+		},
+	}, {
+		name: "duplicate with clause",
+		args: args{s: `SELECT Id FROM Account WITH SECURITY_ENFORCED WITH USER_MODE`},
+		want: parser.ParseError{
+			Code:      types.ParseErrorCode_Syntax,
+			Message:   "Duplicate 'with' clause found",
+			Offset:    46,
+			Line:      1,
+			Col:       47,
+			EndOffset: 60,
+			EndLine:   1,
+			EndCol:    61,
+		},
+	}, {
+		name: "duplicate sosl with clause",
+		args: args{s: `FIND {a} WITH SECURITY_ENFORCED WITH USER_MODE`},
+		want: parser.ParseError{
+			Code:      types.ParseErrorCode_Syntax,
+			Message:   "Duplicate 'with' clause found",
+			Offset:    32,
+			Line:      1,
+			Col:       33,
+			EndOffset: 46,
+			EndLine:   1,
+			EndCol:    47,
+		},
+	}, {
+		name: "normalize data category",
+		args: args{s: "SELECT Title FROM KnowledgeArticleVersion\nWITH DATA CATEGORY Geo__c AT usa__c AND geo__c AT uk__c"},
+		want: parser.ParseError{
+			Code:      types.ParseErrorCode_Normalize,
+			Message:   "Duplicate data category group found: geo__c",
+			Offset:    82,
+			Line:      2,
+			Col:       41,
+			EndOffset: 97,
+			EndLine:   2,
+			EndCol:    56,
+		},
+	}, {
+		name: "normalize sosl data category",
+		args: args{s: "FIND {a} WITH DATA CATEGORY Geo__c AT usa__c AND geo__c AT uk__c"},
+		want: parser.ParseError{
+			Code:      types.ParseErrorCode_Normalize,
+			Message:   "Duplicate data category group found: geo__c",
+			Offset:    49,
+			Line:      1,
+			Col:       50,
+			EndOffset: 64,
+			EndLine:   1,
+			EndCol:    65,
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			if strings.HasPrefix(tt.args.s, "FIND") {
+				_, err = parser.ParseSosl(tt.args.s)
+			} else {
+				_, err = parser.Parse(tt.args.s)
+			}
+
+			var perr *parser.ParseError
+			if !errors.As(err, &perr) {
+				t.Errorf("Parse() error = %v, want *ParseError", err)
+				return
+			}
+			if perr.Code != tt.want.Code {
+				t.Errorf("Parse() error code = %v, want %v", perr.Code, tt.want.Code)
+			}
+			if tt.want.Message == "" {
+				return
+			}
+
+			got := *perr
+			got.Excerpt = ""
+			got.Err = nil
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() error = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRecovery(t *testing.T) {
+	type args struct {
+		s string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		offsets []int
+		wantErr bool
+	}{{
+		name:    "no errors",
+		args:    args{s: "SELECT Id FROM Account"},
+		want:    "SELECT Id FROM Account",
+		offsets: []int{},
+	}, {
+		name:    "field list",
+		args:    args{s: "SELECT Id, Name, FROM Account WHERE Name LIKE 'a%' GROUP Id LIMIT 10"},
+		want:    "SELECT Id, Name FROM Account WHERE Name LIKE 'a%' LIMIT 10",
+		offsets: []int{16, 57},
+	}, {
+		name:    "multiple clauses",
+		args:    args{s: "SELECT Id, 123abc FROM Account WHERE (a = 1 and b = ) and c = 2 ORDER BY Id LIMIT x"},
+		want:    "SELECT Id FROM Account WHERE a = 1 AND c = 2 ORDER BY Id",
+		offsets: []int{10, 52, 82},
+	}, {
+		name:    "closing parenthesis",
+		args:    args{s: "SELECT Id, COUNT( FROM Account"},
+		want:    "SELECT Id FROM Account",
+		offsets: []int{17},
+	}, {
+		name:    "duplicated operator",
+		args:    args{s: "SELECT Id FROM Contact WHERE a = = 1 ORDER BY"},
+		want:    "SELECT Id FROM Contact",
+		offsets: []int{33, 42},
+	}, {
+		name:    "keyword operand",
+		args:    args{s: "SELECT Id FROM Contact WHERE a = ORDER BY Name"},
+		want:    "SELECT Id FROM Contact ORDER BY Name",
+		offsets: []int{33},
+	}, {
+		name:    "logical operators",
+		args:    args{s: "SELECT Id FROM Contact WHERE (a = = 1 OR b = 2) AND c = 3"},
+		want:    "SELECT Id FROM Contact WHERE b = 2 AND c = 3",
+		offsets: []int{34},
+	}, {
+		name:    "group without by",
+		args:    args{s: "SELECT Id FROM Contact GROUP Name ORDER BY Id"},
+		want:    "SELECT Id FROM Contact ORDER BY Id",
+		offsets: []int{29},
+	}, {
+		name:    "empty subquery condition",
+		args:    args{s: "SELECT Id, (SELECT x FROM y WHERE ) FROM Contact"},
+		want:    "SELECT Id, (SELECT x FROM y) FROM Contact",
+		offsets: []int{34},
+	}, {
+		name:    "sosl",
+		args:    args{s: "FIND {a} RETURNING Account(Id, ), Contact LIMIT x"},
+		want:    "FIND {a} RETURNING Account(Id), Contact(Id)",
+		offsets: []int{30, 48},
+	}, {
+		name:    "nothing parsed",
+		args:    args{s: "SELECT FROM"},
+		offsets: []int{7},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			var err error
+			if strings.HasPrefix(tt.args.s, "FIND") {
+				var q *types.SoslQuery
+				q, err = parser.ParseSoslWithOptions(tt.args.s, parser.ParseOptions{Recovery: true})
+				if q != nil {
+					got, _ = parser.FormatSosl(q)
+				}
+			} else {
+				var q *types.SoqlQuery
+				q, err = parser.ParseWithOptions(tt.args.s, parser.ParseOptions{Recovery: true})
+				if q != nil {
+					got, _ = parser.Format(q)
+				}
+			}
+
+			if (got == "") != tt.wantErr {
+				t.Errorf("Parse() = %v, wantErr %v", got, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Parse() = %v, want %v", got, tt.want)
+			}
+
+			offsets := []int{}
+			var errs parser.ParseErrorList
+			if errors.As(err, &errs) {
+				for _, e := range errs {
+					offsets = append(offsets, e.Offset)
+				}
+			} else if err != nil {
+				t.Errorf("Parse() error = %v, want ParseErrorList", err)
+			}
+			if !reflect.DeepEqual(offsets, tt.offsets) {
+				t.Errorf("Parse() error offsets = %v, want %v", offsets, tt.offsets)
+			}
+		})
+	}
+}
+
+func TestSourceSpan(t *testing.T) {
+	s := "SELECT Id, CONCAT(Name, 'x') n,\n  Account.Name\nFROM Contact c\nWHERE (Amount + 1) * 2 > 10 AND (Name = 'a' OR Id IN ('x'))\nORDER BY Name DESC NULLS LAST"
+	got, err := parser.Parse(s)
+	if err != nil {
+		t.Errorf("Parse() error = %v", err)
+		return
+	}
+
+	text := func(span *types.SoqlSourceSpan) string {
+		if span == nil {
+			return "<nil>"
+		}
+		return s[span.Offset:span.EndOffset]
+	}
+
+	tests := []struct {
+		name string
+		span *types.SoqlSourceSpan
+		want string
+	}{
+		{name: "field", span: got.Fields[0].Span, want: "Id"},
+		{name: "function", span: got.Fields[1].Span, want: "CONCAT(Name, 'x') n"},
+		{name: "parameter", span: got.Fields[1].Parameters[1].Span, want: "'x'"},
+		{name: "relationship field", span: got.Fields[2].Span, want: "Account.Name"},
+		{name: "not selected field", span: got.Fields[3].Span, want: "Name"},
+		{name: "object", span: got.From[0].Span, want: "Contact c"},
+		{name: "implicit object", span: got.From[1].Span, want: "Account.Name"},
+		{name: "operand", span: got.Where[0].Span, want: "(Amount + 1) * 2"},
+		{name: "condition", span: got.Where[2].Span, want: "(Amount + 1) * 2 > 10"},
+		{name: "parenthesized condition", span: got.Where[9].Span, want: "(Name = 'a' OR Id IN ('x'))"},
+		{name: "root condition", span: got.Where[10].Span, want: "(Amount + 1) * 2 > 10 AND (Name = 'a' OR Id IN ('x'))"},
+		{name: "order by", span: got.OrderBy[0].Span, want: "Name DESC NULLS LAST"},
+		{name: "order by field", span: got.OrderBy[0].Field.Span, want: "Name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if text(tt.span) != tt.want {
+				t.Errorf("Span = %v, want %v", text(tt.span), tt.want)
+			}
+		})
+	}
+
+	want := types.SoqlSourceSpan{Offset: 34, Line: 2, Col: 3, EndOffset: 46, EndLine: 2, EndCol: 15}
+	if !reflect.DeepEqual(*got.Fields[2].Span, want) {
+		t.Errorf("Span = %+v, want %+v", *got.Fields[2].Span, want)
+	}
+}
@@ -0,0 +1,16 @@
+package postprocess
+
+import (
+	. "github.com/shellyln/go-open-soql-parser/soql/parser/types"
+)
+
+// Error of the normalization.
+// Span is the source span of the node that caused the error; If it is nil, the position is unknown.
+type NormalizeError struct {
+	Message string
+	Span    *SoqlSourceSpan
+}
+
+func (e *NormalizeError) Error() string {
+	return e.Message
+}
@@ -2,7 +2,6 @@
 package postprocess
 
 import (
-	"errors"
 	"sort"
 	"strings"
 
@@ -25,6 +24,8 @@ type normalizeQueryContext struct {
 	functions          map[string]struct{}
 	parameters         map[string]struct{}
 	dateTimeLiterals   map[string]struct{}
+	dataCategoryGroups map[string]struct{}
+	currencyCodes      map[string]struct{}
 }
 
 func (ctx *normalizeQueryContext) normalizeQuery(
@@ -40,6 +41,11 @@ func (ctx *normalizeQueryContext) normalizeQuery(
 	var parentQueryId int
 	if callParentQuery != nil {
 		parentQueryId = callParentQuery.QueryId
+		if callParentQuery.AllRows {
+			// The 'all rows' clause can only be written in the primary query
+			// and it is also applied to the subqueries.
+			q.AllRows = true
+		}
 	}
 	ctx.queryGraph[q.QueryId] = SoqlQueryGraphLeaf{
 		ParentQueryId: parentQueryId,
@@ -48,15 +54,28 @@ func (ctx *normalizeQueryContext) normalizeQuery(
 		Query:         q,
 	}
 
+	if len(q.With.DataCategory) != 0 {
+		groups := make(map[string]struct{})
+		for i := 0; i < len(q.With.DataCategory); i++ {
+			key := strings.ToLower(q.With.DataCategory[i].GroupName)
+			if _, ok := groups[key]; ok {
+				return &NormalizeError{Span: q.With.DataCategory[i].Span, Message: "Duplicate data category group found: " + q.With.DataCategory[i].GroupName}
+			}
+			groups[key] = struct{}{}
+			ctx.dataCategoryGroups[key] = struct{}{}
+		}
+	}
+
 	var primaryObjectName []string // TODO: name is not good? it is primary or parent object
 
 	if qPlace == soqlQueryPlace_Primary || qPlace == soqlQueryPlace_ConditionalOperand {
 		primaryObjectName = q.From[0].Name
 		if len(primaryObjectName) != 1 {
-			return errors.New(
-				"The name of the primary object is qualified by the parent object name: " +
+			return &NormalizeError{
+				Span: q.From[0].Span,
+				Message: "The name of the primary object is qualified by the parent object name: " +
 					strings.Join(primaryObjectName, "."),
-			)
+			}
 		}
 
 		objNameMap = make(map[string][]string) // dotted name (include alias) -> fully qualified name
@@ -99,7 +118,7 @@ func (ctx *normalizeQueryContext) normalizeQuery(
 			if _, ok := objectAliasMap[aliasName]; !ok {
 				objectAliasMap[aliasName] = &q.From[i]
 			} else {
-				return errors.New("Duplicate object alias name found: " + object.AliasName)
+				return &NormalizeError{Span: object.Span, Message: "Duplicate object alias name found: " + object.AliasName}
 			}
 		}
 	}
@@ -123,7 +142,14 @@ func (ctx *normalizeQueryContext) normalizeQuery(
 				return err
 			}
 			q.GroupBy[i] = field
-			groupingFields[field.Key] = struct{}{}
+
+			key := makeGroupingKey(&field)
+			if field.Type == SoqlFieldInfo_Function {
+				if _, ok := groupingFields[key]; ok {
+					return &NormalizeError{Span: field.Span, Message: "Duplicate field found in Group by clause: " + strings.Join(field.Name, ".")}
+				}
+			}
+			groupingFields[key] = struct{}{}
 		}
 	}
 
@@ -154,7 +180,7 @@ func (ctx *normalizeQueryContext) normalizeQuery(
 			if _, ok := fieldAliasMap[aliasName]; !ok {
 				fieldAliasMap[aliasName] = &q.Fields[i]
 			} else {
-				return errors.New("Duplicate field alias name found: " + field.AliasName)
+				return &NormalizeError{Span: field.Span, Message: "Duplicate field alias name found: " + field.AliasName}
 			}
 		}
 	}
@@ -167,7 +193,7 @@ func (ctx *normalizeQueryContext) normalizeQuery(
 				if p, ok := fieldAliasMap[field.Name[len(field.Name)-1]]; ok {
 					delete(groupingFields, field.Key)
 					q.GroupBy[i] = *p
-					groupingFields[p.Key] = struct{}{}
+					groupingFields[makeGroupingKey(p)] = struct{}{}
 				}
 			}
 		}
@@ -202,7 +228,7 @@ func (ctx *normalizeQueryContext) normalizeQuery(
 
 	if q.Having != nil {
 		if q.GroupBy == nil {
-			return errors.New("Group by clause not found: " + strings.Join(primaryObjectName, "."))
+			return &NormalizeError{Span: q.Having[0].Span, Message: "Group by clause not found: " + strings.Join(primaryObjectName, ".")}
 		}
 
 		q.Having = distributeNotOperators(q.Having)
@@ -261,6 +287,8 @@ func (ctx *normalizeQueryContext) normalizeQuery(
 		}
 	}
 
+	applyPolymorphicTypeFilters(q)
+
 	sort.SliceStable(q.From[1:], func(i, j int) bool {
 		return len(q.From[i+1].Name) < len(q.From[j+1].Name)
 	})
@@ -292,16 +320,17 @@ func (ctx *normalizeQueryContext) normalizeQuery(
 		}
 
 		ctx.viewGraph[q.From[i].ViewId] = SoqlViewGraphLeaf{
-			Name:         q.From[i].Name[len(q.From[i].Name)-1],
-			ParentViewId: q.From[i].ParentViewId,
-			QueryId:      q.QueryId,
-			Depth:        objDepth,
-			QueryDepth:   queryDepth,
-			Many:         qPlace == soqlQueryPlace_Select && i == 0,
-			InnerJoin:    false, // set later
-			NonResult:    qPlace == soqlQueryPlace_ConditionalOperand,
-			Object:       &q.From[i],
-			Query:        q,
+			Name:            q.From[i].Name[len(q.From[i].Name)-1],
+			ParentViewId:    q.From[i].ParentViewId,
+			QueryId:         q.QueryId,
+			Depth:           objDepth,
+			QueryDepth:      queryDepth,
+			Many:            qPlace == soqlQueryPlace_Select && i == 0,
+			InnerJoin:       false, // set later
+			NonResult:       qPlace == soqlQueryPlace_ConditionalOperand,
+			PolymorphicType: q.From[i].PolymorphicType,
+			Object:          &q.From[i],
+			Query:           q,
 		}
 	}
 
@@ -313,9 +342,11 @@ func (ctx *normalizeQueryContext) normalizeQuery(
 		usedColumnIds := make(map[int]struct{})
 		for i := 0; i < len(q.GroupBy); i++ {
 			if _, ok := usedColumnIds[q.GroupBy[i].ColumnId]; ok {
-				return errors.New(
-					"Duplicate field found in Group by clause: " +
-						strings.Join(q.GroupBy[i].Name, "."))
+				return &NormalizeError{
+					Span: q.GroupBy[i].Span,
+					Message: "Duplicate field found in Group by clause: " +
+						strings.Join(q.GroupBy[i].Name, "."),
+				}
 			}
 			usedColumnIds[q.GroupBy[i].ColumnId] = struct{}{}
 		}
@@ -324,9 +355,11 @@ func (ctx *normalizeQueryContext) normalizeQuery(
 		usedColumnIds := make(map[int]struct{})
 		for i := 0; i < len(q.OrderBy); i++ {
 			if _, ok := usedColumnIds[q.OrderBy[i].Field.ColumnId]; ok {
-				return errors.New(
-					"Duplicate field found in Order by clause: " +
-						strings.Join(q.OrderBy[i].Field.Name, "."))
+				return &NormalizeError{
+					Span: q.OrderBy[i].Field.Span,
+					Message: "Duplicate field found in Order by clause: " +
+						strings.Join(q.OrderBy[i].Field.Name, "."),
+				}
 			}
 			usedColumnIds[q.OrderBy[i].Field.ColumnId] = struct{}{}
 		}
@@ -363,18 +396,20 @@ func (ctx *normalizeQueryContext) normalizeQuery(
 	}
 
 	if q.OffsetAndLimit.OffsetParamName != "" {
-		ctx.parameters[q.OffsetAndLimit.OffsetParamName] = struct{}{}
+		ctx.parameters[bindRootName(q.OffsetAndLimit.OffsetParamName)] = struct{}{}
 	}
 	if q.OffsetAndLimit.LimitParamName != "" {
-		ctx.parameters[q.OffsetAndLimit.LimitParamName] = struct{}{}
+		ctx.parameters[bindRootName(q.OffsetAndLimit.LimitParamName)] = struct{}{}
 	}
 
 	if q.IsAggregation {
 		for i := 0; i < len(q.Fields); i++ {
 			if q.Fields[i].Type == SoqlFieldInfo_SubQuery {
-				return errors.New(
-					"Subquery on aggregation query is not allowed: " +
-						strings.Join(q.From[0].Name, "."))
+				return &NormalizeError{
+					Span: q.Fields[i].Span,
+					Message: "Subquery on aggregation query is not allowed: " +
+						strings.Join(q.From[0].Name, "."),
+				}
 			}
 		}
 	}
@@ -454,6 +489,8 @@ func Normalize(q *SoqlQuery) error {
 		functions:          make(map[string]struct{}),
 		parameters:         make(map[string]struct{}),
 		dateTimeLiterals:   make(map[string]struct{}),
+		dataCategoryGroups: make(map[string]struct{}),
+		currencyCodes:      make(map[string]struct{}),
 	}
 
 	if err := ctx.normalizeQuery(soqlQueryPlace_Primary, q, nil, 1, nil); err != nil {
@@ -501,6 +538,8 @@ func Normalize(q *SoqlQuery) error {
 	q.Meta.Functions = ctx.functions
 	q.Meta.Parameters = ctx.parameters
 	q.Meta.DateTimeLiterals = ctx.dateTimeLiterals
+	q.Meta.DataCategoryGroups = ctx.dataCategoryGroups
+	q.Meta.CurrencyCodes = ctx.currencyCodes
 
 	return nil
 }
@@ -2,7 +2,8 @@ package postprocess
 
 import (
 	"encoding/base64"
-	"errors"
+	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/shellyln/go-nameutil/nameutil"
@@ -18,6 +19,108 @@ type normalizeFieldNameConf struct {
 	allowUnregisteredObject bool
 }
 
+// Arithmetic and concatenation operators are represented as the functions.
+func isOperatorFunctionName(funcName string) bool {
+	switch funcName {
+	case "+", "-", "*", "/", "||":
+		return true
+	}
+	return false
+}
+
+// Returns the root variable name of the bind expression (e.g. "acc" for "acc.Contacts[0].Id").
+func bindRootName(expr string) string {
+	if i := strings.IndexAny(expr, ".(["); i >= 0 {
+		return expr[:i]
+	}
+	return expr
+}
+
+// Returns the key to identify the expression of the grouping field.
+// The function is identified by the function name and the keys of the parameters.
+func makeGroupingKey(field *SoqlFieldInfo) string {
+	switch field.Type {
+	case SoqlFieldInfo_Field:
+		return field.Key
+	case SoqlFieldInfo_Function:
+		{
+			params := make([]string, 0, len(field.Parameters))
+			for i := 0; i < len(field.Parameters); i++ {
+				params = append(params, makeGroupingKey(&field.Parameters[i]))
+			}
+			return strings.ToLower(strings.Join(field.Name, ".")) + "(" + strings.Join(params, ",") + ")"
+		}
+	case SoqlFieldInfo_ParameterizedValue:
+		return ":" + strings.Join(field.Name, ".")
+	case SoqlFieldInfo_DateTimeLiteralName:
+		return strings.ToLower(strings.Join(field.Name, "."))
+	default:
+		return strconv.Itoa(int(field.Type)) + ":" + fmt.Sprint(field.Value)
+	}
+}
+
+func isAggregateFunctionName(funcName string) bool {
+	switch funcName {
+	case "avg", "count", "count_distinct", "grouping", "max", "min", "sum":
+		return true
+	}
+	return false
+}
+
+// Returns true if the field or the function is the grouping field (or its alias).
+func isGroupingField(field *SoqlFieldInfo, q *SoqlQuery, groupingFields map[string]struct{}) bool {
+	if _, ok := groupingFields[makeGroupingKey(field)]; ok {
+		return true
+	}
+	if field.AliasName != "" {
+		nm := make([]string, 0, len(q.From[0].Name)+1)
+		nm = append(nm, q.From[0].Name...)
+		nm = append(nm, field.AliasName)
+		_, ok := groupingFields[nameutil.MakeDottedKeyIgnoreCase(nm, len(nm))]
+		return ok
+	}
+	return false
+}
+
+// Checks the function of the aggregation result and sets the Aggregated flag of it and its nested functions.
+// The function should be the aggregate function, the grouping field,
+// or the function (including operators) that is applied to them.
+func checkGroupedFunction(field *SoqlFieldInfo, q *SoqlQuery, groupingFields map[string]struct{}) error {
+	if isGroupingField(field, q, groupingFields) {
+		field.Aggregated = false
+		return nil
+	}
+
+	funcName := ""
+	if len(field.Name) == 1 {
+		funcName = strings.ToLower(field.Name[0])
+	}
+	if isAggregateFunctionName(funcName) {
+		field.Aggregated = true
+		return nil
+	}
+	field.Aggregated = false
+
+	for i := 0; i < len(field.Parameters); i++ {
+		param := &field.Parameters[i]
+		switch param.Type {
+		case SoqlFieldInfo_Field:
+			if param.Key != "" && !isGroupingField(param, q, groupingFields) {
+				return &NormalizeError{
+					Span: field.Span,
+					Message: "The function must be an aggregate function or included in a Group By clause: " +
+						strings.Join(field.Name, "."),
+				}
+			}
+		case SoqlFieldInfo_Function:
+			if err := checkGroupedFunction(param, q, groupingFields); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func preScanFunctionFields(field *SoqlFieldInfo, q *SoqlQuery) {
 	switch field.Type {
 	case SoqlFieldInfo_Function:
@@ -87,16 +190,18 @@ func (ctx *normalizeQueryContext) normalizeFieldName(
 						// Register an unregistered object name.
 
 						if !conf.allowUnregisteredObject {
-							return errors.New(
-								"Unregistered object names are not allowed: " +
+							return &NormalizeError{
+								Span: field.Span,
+								Message: "Unregistered object names are not allowed: " +
 									strings.Join(field.Name, ".") + " at " + strings.Join(q.From[0].Name, "."),
-							)
+							}
 						}
 
 						o := SoqlObjectInfo{
 							// TODO: Type (ParentRelationship if conf.isSelectClause otherwise ConditionalOperand)
 							Name: s,
 							Key:  nameutil.MakeDottedKeyIgnoreCase(s, len(s)),
+							Span: field.Span,
 						}
 						objNameMap[key] = s
 						q.From = append(q.From, o)
@@ -124,18 +229,20 @@ func (ctx *normalizeQueryContext) normalizeFieldName(
 			if conf.isSelectClause {
 				if !conf.isFunctionParameter && (len(field.Name) <= len(q.From[0].Name)) {
 					// TODO: Is `!conf.isFunctionParameter` right condition? // <- BUG: It's a bug!
-					return errors.New(
-						"The ancestor object item is not allowed to be selected: " +
+					return &NormalizeError{
+						Span: field.Span,
+						Message: "The ancestor object item is not allowed to be selected: " +
 							strings.Join(field.Name, ".") + " at " + strings.Join(q.From[0].Name, "."),
-					)
+					}
 				}
 
 				for i := 0; i < len(q.From[0].Name); i++ {
 					if q.From[0].Name[i] != field.Name[i] {
-						return errors.New(
-							"The siblings object item is not allowed to be selected: " +
+						return &NormalizeError{
+							Span: field.Span,
+							Message: "The siblings object item is not allowed to be selected: " +
 								strings.Join(field.Name, ".") + " at " + strings.Join(q.From[0].Name, "."),
-						)
+						}
 					}
 				}
 			}
@@ -154,7 +261,7 @@ func (ctx *normalizeQueryContext) normalizeFieldName(
 							_, ok = groupingFields[nameutil.MakeDottedKeyIgnoreCase(nm, len(nm))]
 						}
 						if !ok {
-							return errors.New("The item must be included in a Group By clause: " + strings.Join(field.Name, "."))
+							return &NormalizeError{Span: field.Span, Message: "The item must be included in a Group By clause: " + strings.Join(field.Name, ".")}
 						}
 					}
 				}
@@ -172,35 +279,35 @@ func (ctx *normalizeQueryContext) normalizeFieldName(
 				// Check function names not allowed in nested
 				switch funcName {
 				case "fields":
-					return errors.New("The function name is not allowed in nested function: " + field.Name[0])
+					return &NormalizeError{Span: field.Span, Message: "The function name is not allowed in nested function: " + field.Name[0]}
 				}
 			}
 			if !conf.isSelectClause {
 				// Check function names not allowed in conditions
 				switch funcName {
-				case "count", "count_distinct":
+				case "count", "count_distinct", "grouping":
 					if conf.isHavingClause {
 						break
 					}
 					fallthrough
 				case "fields":
-					return errors.New("The function name is not allowed in conditional clause: " + field.Name[0])
+					return &NormalizeError{Span: field.Span, Message: "The function name is not allowed in conditional clause: " + field.Name[0]}
 				}
 			}
 			if q.IsAggregation {
 				switch funcName {
 				case "fields":
-					return errors.New("The function name is not allowed in aggregation result: " + field.Name[0])
+					return &NormalizeError{Span: field.Span, Message: "The function name is not allowed in aggregation result: " + field.Name[0]}
 				}
 			}
 
 			switch funcName {
 			case "fields":
 				if len(field.Parameters) != 1 {
-					return errors.New("Field set 'Fields()' requires a single parameter")
+					return &NormalizeError{Span: field.Span, Message: "Field set 'Fields()' requires a single parameter"}
 				}
 				if field.Parameters[0].Type != SoqlFieldInfo_Field {
-					return errors.New("Field set 'Fields()' parameter must be a name")
+					return &NormalizeError{Span: field.Parameters[0].Span, Message: "Field set 'Fields()' parameter must be a name"}
 				}
 
 				field.Type = SoqlFieldInfo_FieldSet
@@ -214,9 +321,11 @@ func (ctx *normalizeQueryContext) normalizeFieldName(
 				fullyQualifiedName, ok := objNameMap[key]
 
 				if !ok {
-					return errors.New(
-						"Field set 'Fields()' parameter refers unknown object: " +
-							strings.Join(field.Name, "."))
+					return &NormalizeError{
+						Span: field.Span,
+						Message: "Field set 'Fields()' parameter refers unknown object: " +
+							strings.Join(field.Name, "."),
+					}
 				}
 
 				fqnLen := len(fullyQualifiedName)
@@ -232,25 +341,37 @@ func (ctx *normalizeQueryContext) normalizeFieldName(
 					q.IsAggregation = true
 				case 1:
 					if field.Parameters[0].Type != SoqlFieldInfo_Field {
-						return errors.New("Function 'Count()' parameter must be a name")
+						return &NormalizeError{Span: field.Parameters[0].Span, Message: "Function 'Count()' parameter must be a name"}
 					}
 					field.Aggregated = true
 					q.IsAggregation = true
 				default:
-					return errors.New("Function 'Count()' requires 0 or 1 parameter")
+					return &NormalizeError{Span: field.Span, Message: "Function 'Count()' requires 0 or 1 parameter"}
 				}
 
 			case "count_distinct":
 				switch len(field.Parameters) {
 				case 1:
 					if field.Parameters[0].Type != SoqlFieldInfo_Field {
-						return errors.New("Function 'Count()' parameter must be a name")
+						return &NormalizeError{Span: field.Parameters[0].Span, Message: "Function 'Count()' parameter must be a name"}
 					}
 					field.Aggregated = true
 					q.IsAggregation = true
 				default:
-					return errors.New("Function 'Count_distinct()' requires 1 parameter")
+					return &NormalizeError{Span: field.Span, Message: "Function 'Count_distinct()' requires 1 parameter"}
+				}
+
+			case "grouping":
+				if len(field.Parameters) != 1 {
+					return &NormalizeError{Span: field.Span, Message: "Function 'Grouping()' requires 1 parameter"}
 				}
+				if field.Parameters[0].Type != SoqlFieldInfo_Field {
+					return &NormalizeError{Span: field.Parameters[0].Span, Message: "Function 'Grouping()' parameter must be a name"}
+				}
+				if q.GroupingMode != SoqlGroupingMode_Rollup && q.GroupingMode != SoqlGroupingMode_Cube {
+					return &NormalizeError{Span: field.Span, Message: "Function 'Grouping()' requires the Group By Rollup or Group By Cube clause"}
+				}
+				field.Aggregated = true
 			}
 
 			for i := 0; i < len(field.Parameters); i++ {
@@ -268,41 +389,17 @@ func (ctx *normalizeQueryContext) normalizeFieldName(
 				}
 			}
 
-			if conf.isHavingClause || (conf.isSelectClause && q.IsAggregation) {
-				// If the parameter contains items and not all parameters are functions, it is an aggregate function.
-
-				aggregated := false
-			FN_HAVING_OUTER:
-				for i := 0; i < len(field.Parameters); i++ {
-					switch field.Parameters[i].Type {
-					case SoqlFieldInfo_Field:
-						aggregated = true
-					case SoqlFieldInfo_Function:
-						aggregated = false
-						break FN_HAVING_OUTER
-					}
+			if funcName == "grouping" {
+				// The parameter of the grouping function should be a grouping field.
+				param := &field.Parameters[0]
+				if _, ok := groupingFields[param.Key]; !ok {
+					return &NormalizeError{Span: param.Span, Message: "The item must be included in a Group By clause: " + strings.Join(param.Name, ".")}
 				}
-				field.Aggregated = aggregated
+			}
 
-				for i := 0; i < len(field.Parameters); i++ {
-					// TODO: FIXIT: Extract this condition outside the loop.
-					if aggregated {
-						continue
-					}
-					param := &field.Parameters[i]
-					if param.Type == SoqlFieldInfo_Field && param.Key != "" {
-						if _, ok := groupingFields[param.Key]; !ok {
-							if param.AliasName != "" {
-								nm := make([]string, 0, len(q.From[0].Name)+1)
-								nm = append(nm, q.From[0].Name...)
-								nm = append(nm, param.AliasName)
-								_, ok = groupingFields[nameutil.MakeDottedKeyIgnoreCase(nm, len(nm))]
-							}
-							if !ok {
-								return errors.New("The item must be included in a Group By clause: " + strings.Join(param.Name, "."))
-							}
-						}
-					}
+			if !conf.isFunctionParameter && (conf.isHavingClause || (conf.isSelectClause && q.IsAggregation)) {
+				if err := checkGroupedFunction(field, q, groupingFields); err != nil {
+					return err
 				}
 			}
 		}
@@ -311,18 +408,38 @@ func (ctx *normalizeQueryContext) normalizeFieldName(
 			field.SubQuery.Parent = q
 		}
 		if err := ctx.normalizeQuery(qPlace, field.SubQuery, q, queryDepth+1, objNameMap); err != nil {
+			return err
+		}
+	case SoqlFieldInfo_TypeOf:
+		if err := ctx.normalizeTypeOfField(
+			field, qPlace, q, queryDepth, objNameMap, groupingFields, conf); err != nil {
+
 			return err
 		}
 	case SoqlFieldInfo_FieldSet:
 		if !conf.isSelectClause || conf.isFunctionParameter {
-			return errors.New(
-				"The fields() is not allowed in conditional clause or function parameter: " +
-					strings.Join(field.Name, "."))
+			return &NormalizeError{
+				Span: field.Span,
+				Message: "The fields() is not allowed in conditional clause or function parameter: " +
+					strings.Join(field.Name, "."),
+			}
 		}
 	case SoqlFieldInfo_ParameterizedValue:
-		ctx.parameters[strings.ToLower(field.Name[0])] = struct{}{}
+		ctx.parameters[strings.ToLower(bindRootName(field.Name[0]))] = struct{}{}
 	case SoqlFieldInfo_DateTimeLiteralName:
 		ctx.dateTimeLiterals[strings.ToLower(field.Name[0])] = struct{}{}
+	case SoqlFieldInfo_Literal_Currency:
+		if v, ok := field.Value.(SoqlCurrency); ok {
+			ctx.currencyCodes[v.Code] = struct{}{}
+		}
+	case SoqlFieldInfo_Literal_List:
+		if items, ok := field.Value.([]SoqlListItem); ok {
+			for i := 0; i < len(items); i++ {
+				if v, ok := items[i].Value.(SoqlCurrency); ok {
+					ctx.currencyCodes[v.Code] = struct{}{}
+				}
+			}
+		}
 	}
 
 	return nil
@@ -355,6 +472,7 @@ func (ctx *normalizeQueryContext) normalizeObjectName(
 				// TODO: Type // ParentRelationship
 				Name: s,
 				Key:  key,
+				Span: object.Span,
 			}
 			objNameMap[key] = s
 			q.From = append(q.From, o)
@@ -168,6 +168,8 @@ func (ctx *normalizeQueryContext) assignColumnIdToField(
 				field.ColumnId = ctx.columnId
 				ctx.columnId++
 			}
+		case SoqlFieldInfo_TypeOf:
+			ctx.assignColumnIdToTypeOfField(field)
 		case SoqlFieldInfo_FieldSet, SoqlFieldInfo_SubQuery:
 			field.ColumnId = ctx.columnId
 			ctx.columnId++
@@ -253,6 +255,7 @@ func (ctx *normalizeQueryContext) assignImplicitAliasNames(q *SoqlQuery, fieldAl
 
 					if _, ok := fieldAliasMap[exprName]; !ok {
 						field.AliasName = exprName
+						field.AutoAlias = true
 						break
 					}
 				}
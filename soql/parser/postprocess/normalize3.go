@@ -1,7 +1,6 @@
 package postprocess
 
 import (
-	"errors"
 	"strings"
 
 	"github.com/shellyln/go-nameutil/nameutil"
@@ -46,9 +45,11 @@ func (ctx *normalizeQueryContext) applyColIndexToFields(q *SoqlQuery, fields []S
 						}
 					}
 					if !isSet {
-						return errors.New(
-							"An incorrect ancestor field of object referred to in the correlated subquery: " +
-								strings.Join(fields[i].Name, "."))
+						return &NormalizeError{
+							Span: fields[i].Span,
+							Message: "An incorrect ancestor field of object referred to in the correlated subquery: " +
+								strings.Join(fields[i].Name, "."),
+						}
 					}
 				}
 			}
@@ -56,6 +57,12 @@ func (ctx *normalizeQueryContext) applyColIndexToFields(q *SoqlQuery, fields []S
 			if err := ctx.applyColIndexToFields(q, fields[i].Parameters); err != nil {
 				return err
 			}
+		case SoqlFieldInfo_TypeOf:
+			for j := range fields[i].Branches {
+				if err := ctx.applyColIndexToFields(q, fields[i].Branches[j].Fields); err != nil {
+					return err
+				}
+			}
 		}
 	}
 	return nil
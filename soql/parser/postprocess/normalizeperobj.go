@@ -17,10 +17,17 @@ func makePerObjectFields(perObjQuery *SoqlQuery, srcFields []SoqlFieldInfo) []So
 		case SoqlFieldInfo_Field, SoqlFieldInfo_FieldSet:
 			{
 				name := srcFields[i].Name
-				if nameutil.NameEqualsIgnoreCase(perObjQuery.From[0].Name, name[:len(name)-1]) {
+				if perObjQuery.From[0].PolymorphicType == "" &&
+					nameutil.NameEqualsIgnoreCase(perObjQuery.From[0].Name, name[:len(name)-1]) {
 					targetFields = append(targetFields, srcFields[i])
 				}
 			}
+		case SoqlFieldInfo_TypeOf:
+			for j := 0; j < len(srcFields[i].Branches); j++ {
+				if srcFields[i].Branches[j].Key == perObjQuery.From[0].Key {
+					targetFields = append(targetFields, srcFields[i].Branches[j].Fields...)
+				}
+			}
 		case SoqlFieldInfo_Function, SoqlFieldInfo_SubQuery:
 			// NOTE: do nothing
 			//       Because the function is executed locally, not in the adapter.
@@ -321,7 +328,8 @@ func compactConditions(conditions []SoqlCondition) []SoqlCondition {
 func (ctx *normalizeQueryContext) buildPerObjectInfo(q *SoqlQuery) error {
 	for i := 0; i < len(q.From); i++ {
 		perObjQuery := &SoqlQuery{
-			From: []SoqlObjectInfo{q.From[i]},
+			From:    []SoqlObjectInfo{q.From[i]},
+			AllRows: q.AllRows,
 		}
 		q.From[i].PerObjectQuery = perObjQuery
 
@@ -340,7 +348,10 @@ func (ctx *normalizeQueryContext) buildPerObjectInfo(q *SoqlQuery) error {
 	for i := 0; i < len(q.From); i++ {
 		perObjQuery := q.From[i].PerObjectQuery
 
-		if len(q.Where) != 0 {
+		// NOTE: Conditions and orders on the polymorphic relationship are
+		//       processed by the view of the relationship, not by the views of each object type.
+
+		if len(q.Where) != 0 && q.From[i].PolymorphicType == "" {
 			q.From[i].HasConditions, perObjQuery.Where, q.From[i].InnerJoin =
 				makePerObjectConditions(perObjQuery, q.Where)
 		}
@@ -348,7 +359,7 @@ func (ctx *normalizeQueryContext) buildPerObjectInfo(q *SoqlQuery) error {
 		// NOTE: GroupBy and Having are not processed.
 		//       Aggregation is not done for multiple objects.
 
-		if q.OrderBy != nil {
+		if q.OrderBy != nil && q.From[i].PolymorphicType == "" {
 			perObjQuery.OrderBy = makePerObjectOrders(perObjQuery, q.OrderBy)
 		}
 
@@ -356,6 +367,7 @@ func (ctx *normalizeQueryContext) buildPerObjectInfo(q *SoqlQuery) error {
 			perObjQuery.OffsetAndLimit = q.OffsetAndLimit
 		}
 
+		perObjQuery.With = q.With
 		perObjQuery.For = q.For
 	}
 	q.From[0].InnerJoin = false
@@ -0,0 +1,66 @@
+package postprocess
+
+import (
+	"strings"
+
+	. "github.com/shellyln/go-open-soql-parser/soql/parser/types"
+)
+
+func mergeMetaKeys(dest, src map[string]struct{}) {
+	for k := range src {
+		dest[k] = struct{}{}
+	}
+}
+
+// Normalize each object of the returning clause as an independent query.
+// The meta information of q is set beforehand.
+func NormalizeSosl(q *SoslQuery) error {
+	objects := make(map[string]struct{})
+
+	q.Meta.Functions = make(map[string]struct{})
+	q.Meta.Parameters = make(map[string]struct{})
+	q.Meta.DateTimeLiterals = make(map[string]struct{})
+	q.Meta.DataCategoryGroups = make(map[string]struct{})
+	q.Meta.CurrencyCodes = make(map[string]struct{})
+
+	for i := 0; i < len(q.Returning); i++ {
+		r := &q.Returning[i]
+
+		key := strings.ToLower(strings.Join(r.From[0].Name, "."))
+		if _, ok := objects[key]; ok {
+			return &NormalizeError{Span: r.From[0].Span, Message: "Duplicate object found in the returning clause: " + strings.Join(r.From[0].Name, ".")}
+		}
+		objects[key] = struct{}{}
+
+		r.Meta = &SoqlQueryMeta{
+			Version: q.Meta.Version,
+			Date:    q.Meta.Date,
+			Source:  q.Meta.Source,
+		}
+
+		if err := Normalize(r); err != nil {
+			return err
+		}
+
+		mergeMetaKeys(q.Meta.Functions, r.Meta.Functions)
+		mergeMetaKeys(q.Meta.Parameters, r.Meta.Parameters)
+		mergeMetaKeys(q.Meta.DateTimeLiterals, r.Meta.DateTimeLiterals)
+		mergeMetaKeys(q.Meta.CurrencyCodes, r.Meta.CurrencyCodes)
+	}
+
+	if len(q.With.DataCategory) != 0 {
+		for i := 0; i < len(q.With.DataCategory); i++ {
+			key := strings.ToLower(q.With.DataCategory[i].GroupName)
+			if _, ok := q.Meta.DataCategoryGroups[key]; ok {
+				return &NormalizeError{Span: q.With.DataCategory[i].Span, Message: "Duplicate data category group found: " + q.With.DataCategory[i].GroupName}
+			}
+			q.Meta.DataCategoryGroups[key] = struct{}{}
+		}
+	}
+
+	if q.LimitParamName != "" {
+		q.Meta.Parameters[bindRootName(q.LimitParamName)] = struct{}{}
+	}
+
+	return nil
+}
@@ -0,0 +1,228 @@
+package postprocess
+
+import (
+	"encoding/base64"
+	"strings"
+
+	"github.com/shellyln/go-nameutil/nameutil"
+	. "github.com/shellyln/go-open-soql-parser/soql/parser/types"
+)
+
+func makePolymorphicObjectKey(relationshipKey string, objectType string) string {
+	// NOTE: ':' is not a character of base64. It does not conflict with the keys of the relationship names.
+	return relationshipKey + ":" + base64.StdEncoding.EncodeToString([]byte(strings.ToLower(objectType)))
+}
+
+func (ctx *normalizeQueryContext) normalizeTypeOfField(
+	field *SoqlFieldInfo, qPlace soqlQueryPlace, q *SoqlQuery, queryDepth int,
+	objNameMap map[string][]string,
+	groupingFields map[string]struct{},
+	conf normalizeFieldNameConf) error {
+
+	if !conf.isSelectClause || conf.isFunctionParameter {
+		return &NormalizeError{
+			Span: field.Span,
+			Message: "The typeof is not allowed in conditional clause or function parameter: " +
+				strings.Join(field.Name, "."),
+		}
+	}
+	if q.IsAggregation {
+		return &NormalizeError{Span: field.Span, Message: "The typeof is not allowed in aggregation result: " + strings.Join(field.Name, ".")}
+	}
+	if field.AliasName != "" {
+		return &NormalizeError{Span: field.Span, Message: "The typeof is not allowed to have an alias name: " + strings.Join(field.Name, ".")}
+	}
+
+	{
+		// Resolve the polymorphic relationship name as the namespace of the field.
+		name := make([]string, len(field.Name), len(field.Name)+1)
+		copy(name, field.Name)
+		relField := SoqlFieldInfo{
+			Type: SoqlFieldInfo_Field,
+			Name: append(name, "Id"),
+			Span: field.Span,
+		}
+
+		if err := ctx.normalizeFieldName(
+			&relField, qPlace, q, queryDepth,
+			objNameMap, groupingFields, conf); err != nil {
+
+			return err
+		}
+
+		field.Name = nameutil.GetNamespaceFromName(relField.Name)
+		field.Key = nameutil.MakeDottedKeyIgnoreCase(field.Name, len(field.Name))
+	}
+
+	objectTypes := make(map[string]struct{})
+
+	for i := 0; i < len(field.Branches); i++ {
+		branch := &field.Branches[i]
+
+		if branch.ObjectType == "" {
+			// `else` branch
+			branch.Key = field.Key
+		} else {
+			objectType := strings.ToLower(branch.ObjectType)
+			if _, ok := objectTypes[objectType]; ok {
+				return &NormalizeError{
+					Span: field.Span,
+					Message: "Duplicate object type found in typeof expression: " +
+						strings.Join(field.Name, ".") + " " + branch.ObjectType,
+				}
+			}
+			objectTypes[objectType] = struct{}{}
+
+			branch.Key = makePolymorphicObjectKey(field.Key, branch.ObjectType)
+
+			found := false
+			for j := 0; j < len(q.From); j++ {
+				if q.From[j].Key == branch.Key {
+					found = true
+					break
+				}
+			}
+			if !found {
+				q.From = append(q.From, SoqlObjectInfo{
+					Name:            field.Name,
+					PolymorphicType: branch.ObjectType,
+					Key:             branch.Key,
+					Span:            field.Span,
+				})
+			}
+		}
+
+		for j := 0; j < len(branch.Fields); j++ {
+			f := &branch.Fields[j]
+
+			if f.Type != SoqlFieldInfo_Field || len(f.Name) != 1 {
+				// TODO: relationship names in the branch
+				return &NormalizeError{
+					Span: f.Span,
+					Message: "The item of the typeof branch should be a field name of the object: " +
+						strings.Join(f.Name, "."),
+				}
+			}
+
+			s := make([]string, 0, len(field.Name)+1)
+			s = append(s, field.Name...)
+			s = append(s, f.Name[0])
+			f.Name = s
+			f.Key = branch.Key + "." + base64.StdEncoding.EncodeToString([]byte(strings.ToLower(f.Name[len(f.Name)-1])))
+		}
+	}
+
+	return nil
+}
+
+func (ctx *normalizeQueryContext) assignColumnIdToTypeOfField(field *SoqlFieldInfo) {
+	field.ColumnId = ctx.columnId
+	ctx.columnId++
+
+	if viewId, ok := ctx.viewIdMap[field.Key]; ok {
+		field.ViewId = viewId
+	}
+
+	for i := 0; i < len(field.Branches); i++ {
+		branch := &field.Branches[i]
+		viewId := ctx.viewIdMap[branch.Key]
+		branch.ViewId = viewId
+
+		for j := 0; j < len(branch.Fields); j++ {
+			f := &branch.Fields[j]
+			if f.ColumnId != 0 {
+				continue
+			}
+
+			if id, ok := ctx.columnIdMap[f.Key]; !ok {
+				f.ColumnId = ctx.columnId
+				ctx.columnIdMap[f.Key] = ctx.columnId
+				ctx.columnId++
+			} else {
+				f.ColumnId = id
+			}
+			f.ViewId = viewId
+		}
+	}
+}
+
+// Find the conditions `Type = 'x'` and `Type in ('x', ...)` on the polymorphic relationships
+// that are operands of the top-level `and` operators.
+// Returns the start position of the operand that ends with `end`.
+func findPolymorphicTypeConditions(
+	conditions []SoqlCondition, end int, isTopLevel bool,
+	relationshipKeys map[string]struct{}, filters map[string][]string) int {
+
+	cond := conditions[end]
+
+	switch cond.Opcode {
+	case SoqlConditionOpcode_Noop, SoqlConditionOpcode_Unknown, SoqlConditionOpcode_FieldInfo:
+		return end
+	case SoqlConditionOpcode_Not:
+		return findPolymorphicTypeConditions(conditions, end-1, false, relationshipKeys, filters)
+	}
+
+	op2Start := findPolymorphicTypeConditions(
+		conditions, end-1, isTopLevel && cond.Opcode == SoqlConditionOpcode_And, relationshipKeys, filters)
+	op1Start := findPolymorphicTypeConditions(
+		conditions, op2Start-1, isTopLevel && cond.Opcode == SoqlConditionOpcode_And, relationshipKeys, filters)
+
+	if !isTopLevel || op1Start != end-2 || op2Start != end-1 {
+		return op1Start
+	}
+
+	op1 := &conditions[end-2].Value
+	op2 := &conditions[end-1].Value
+
+	if conditions[end-2].Opcode != SoqlConditionOpcode_FieldInfo || op1.Type != SoqlFieldInfo_Field ||
+		!strings.EqualFold(op1.Name[len(op1.Name)-1], "Type") {
+		return op1Start
+	}
+
+	ns := nameutil.GetNamespaceFromName(op1.Name)
+	key := nameutil.MakeDottedKeyIgnoreCase(ns, len(ns))
+	if _, ok := relationshipKeys[key]; !ok {
+		return op1Start
+	}
+
+	switch cond.Opcode {
+	case SoqlConditionOpcode_Eq:
+		if op2.Type == SoqlFieldInfo_Literal_String {
+			filters[key] = append(filters[key], op2.Value.(string))
+		}
+	case SoqlConditionOpcode_In:
+		if op2.Type == SoqlFieldInfo_Literal_List {
+			for _, item := range op2.Value.([]SoqlListItem) {
+				if item.Type == SoqlFieldInfo_Literal_String {
+					filters[key] = append(filters[key], item.Value.(string))
+				}
+			}
+		}
+	}
+
+	return op1Start
+}
+
+func applyPolymorphicTypeFilters(q *SoqlQuery) {
+	relationshipKeys := make(map[string]struct{})
+	for i := 0; i < len(q.Fields); i++ {
+		if q.Fields[i].Type == SoqlFieldInfo_TypeOf {
+			relationshipKeys[q.Fields[i].Key] = struct{}{}
+		}
+	}
+	if len(relationshipKeys) == 0 || len(q.Where) == 0 {
+		return
+	}
+
+	filters := make(map[string][]string)
+	findPolymorphicTypeConditions(q.Where, len(q.Where)-1, true, relationshipKeys, filters)
+
+	for i := 0; i < len(q.From); i++ {
+		if q.From[i].PolymorphicType != "" {
+			continue
+		}
+		if filter, ok := filters[q.From[i].Key]; ok {
+			q.From[i].PolymorphicTypeFilter = filter
+		}
+	}
+}
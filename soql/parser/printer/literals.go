@@ -0,0 +1,266 @@
+package printer
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/shellyln/go-open-soql-parser/soql/parser/types"
+)
+
+var reservedWords = map[string]struct{}{
+	"select":   {},
+	"from":     {},
+	"where":    {},
+	"order":    {},
+	"group":    {},
+	"by":       {},
+	"having":   {},
+	"offset":   {},
+	"limit":    {},
+	"with":     {},
+	"for":      {},
+	"using":    {},
+	"and":      {},
+	"or":       {},
+	"not":      {},
+	"like":     {},
+	"in":       {},
+	"includes": {},
+	"excludes": {},
+	"asc":      {},
+	"desc":     {},
+	"nulls":    {},
+	"null":     {},
+	"true":     {},
+	"false":    {},
+	"typeof":   {},
+	"when":     {},
+	"then":     {},
+	"else":     {},
+	"end":      {},
+}
+
+func isSymbolChar(c byte, head bool) bool {
+	switch {
+	case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', c == '$', c == '_':
+		return true
+	case '0' <= c && c <= '9':
+		return !head
+	}
+	return false
+}
+
+func formatSymbol(s string) string {
+	plain := len(s) != 0
+	for i := 0; i < len(s); i++ {
+		if !isSymbolChar(s[i], i == 0) {
+			plain = false
+			break
+		}
+	}
+	if plain {
+		if _, ok := reservedWords[strings.ToLower(s)]; !ok {
+			plain = !isDateTimeLiteralName(s) && !isCurrencyLiteral(s)
+		} else {
+			plain = false
+		}
+	}
+	if plain {
+		return s
+	}
+	return `"` + escapeString(s, '"') + `"`
+}
+
+func formatSymbols(name []string) string {
+	s := make([]string, len(name))
+	for i := 0; i < len(name); i++ {
+		s[i] = formatSymbol(name[i])
+	}
+	return strings.Join(s, ".")
+}
+
+func isDateTimeLiteralName(s string) bool {
+	switch strings.ToUpper(s) {
+	case "THIS_FISCAL_QUARTER", "LAST_FISCAL_QUARTER", "NEXT_FISCAL_QUARTER",
+		"THIS_FISCAL_YEAR", "LAST_FISCAL_YEAR", "NEXT_FISCAL_YEAR",
+		"LAST_90_DAYS", "NEXT_90_DAYS",
+		"THIS_QUARTER", "LAST_QUARTER", "NEXT_QUARTER",
+		"LAST_MONTH", "THIS_MONTH", "NEXT_MONTH",
+		"LAST_WEEK", "THIS_WEEK", "NEXT_WEEK",
+		"THIS_YEAR", "LAST_YEAR", "NEXT_YEAR",
+		"YESTERDAY", "TOMORROW", "TODAY":
+		return true
+	}
+	return false
+}
+
+// The symbol that looks like a currency literal (e.g. USD5000).
+func isCurrencyLiteral(s string) bool {
+	if len(s) < 4 || !IsCurrencyCode(s[:3]) {
+		return false
+	}
+	for i := 3; i < len(s); i++ {
+		if s[i] < '0' || '9' < s[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func escapeString(s string, quote rune) string {
+	var sb strings.Builder
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch c {
+		case '\\':
+			if i+1 < len(runes) && (runes[i+1] == '_' || runes[i+1] == '%') {
+				// Escape sequence for Like expression
+				sb.WriteRune(c)
+				i++
+				sb.WriteRune(runes[i])
+			} else {
+				sb.WriteString(`\\`)
+			}
+		case quote:
+			sb.WriteRune('\\')
+			sb.WriteRune(c)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\v':
+			sb.WriteString(`\v`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\b':
+			sb.WriteString(`\b`)
+		case '\f':
+			sb.WriteString(`\f`)
+		default:
+			if c < 0x20 || c == 0x7f {
+				sb.WriteString(fmt.Sprintf(`\x%02x`, c))
+			} else {
+				sb.WriteRune(c)
+			}
+		}
+	}
+	return sb.String()
+}
+
+func formatInt(v int64) string {
+	return strconv.FormatInt(v, 10)
+}
+
+func formatFloat(v float64) (string, error) {
+	if math.IsInf(v, 0) || math.IsNaN(v) {
+		// Infinity and NaN are not valid SOQL literals.
+		return "", errors.New("Float literal is not a finite number: " + strconv.FormatFloat(v, 'g', -1, 64))
+	}
+	// Exponent notation is not a valid SOQL literal.
+	s := strconv.FormatFloat(v, 'f', -1, 64)
+	if !strings.Contains(s, ".") {
+		s += ".0"
+	}
+	return s, nil
+}
+
+func formatDateTimeLiteralName(v SoqlDateTimeLiteralName) string {
+	if strings.Contains(v.Name, "_N_") {
+		return v.Name + ":" + strconv.Itoa(v.N)
+	}
+	return v.Name
+}
+
+func formatListItem(item *SoqlListItem) (string, error) {
+	switch item.Type {
+	case SoqlFieldInfo_ParameterizedValue:
+		if v, ok := item.Value.(string); ok {
+			return ":" + v, nil
+		}
+	case SoqlFieldInfo_DateTimeLiteralName:
+		switch v := item.Value.(type) {
+		case SoqlDateTimeLiteralName:
+			return formatDateTimeLiteralName(v), nil
+		case string:
+			return v, nil
+		}
+	default:
+		return formatLiteral(item.Type, item.Value)
+	}
+	return "", errors.New("Unexpected list item value: " + item.Type.String())
+}
+
+func formatLiteral(ty SoqlFieldInfoType, value interface{}) (string, error) {
+	switch ty {
+	case SoqlFieldInfo_Literal_Null:
+		return "null", nil
+	case SoqlFieldInfo_Literal_Int:
+		switch v := value.(type) {
+		case int64:
+			return formatInt(v), nil
+		case int:
+			return strconv.Itoa(v), nil
+		}
+	case SoqlFieldInfo_Literal_Float:
+		switch v := value.(type) {
+		case float64:
+			return formatFloat(v)
+		case float32:
+			return formatFloat(float64(v))
+		}
+	case SoqlFieldInfo_Literal_Decimal:
+		if v, ok := value.(SoqlDecimal); ok {
+			return string(v), nil
+		}
+	case SoqlFieldInfo_Literal_Currency:
+		if v, ok := value.(SoqlCurrency); ok {
+			return v.Code + string(v.Value), nil
+		}
+	case SoqlFieldInfo_Literal_Bool:
+		if v, ok := value.(bool); ok {
+			return strconv.FormatBool(v), nil
+		}
+	case SoqlFieldInfo_Literal_String:
+		if v, ok := value.(string); ok {
+			return "'" + escapeString(v, '\'') + "'", nil
+		}
+	case SoqlFieldInfo_Literal_Date:
+		if v, ok := value.(time.Time); ok {
+			return v.Format("2006-01-02"), nil
+		}
+	case SoqlFieldInfo_Literal_DateTime:
+		switch v := value.(type) {
+		case SoqlDateTimeValue:
+			return v.Source, nil
+		case time.Time:
+			return v.Format("2006-01-02T15:04:05.999999999Z07:00"), nil
+		}
+	case SoqlFieldInfo_Literal_Time:
+		switch v := value.(type) {
+		case SoqlDateTimeValue:
+			return v.Source, nil
+		case time.Time:
+			return v.Format("15:04:05.999999999"), nil
+		}
+	case SoqlFieldInfo_Literal_List:
+		if v, ok := value.([]SoqlListItem); ok {
+			items := make([]string, len(v))
+			for i := 0; i < len(v); i++ {
+				s, err := formatListItem(&v[i])
+				if err != nil {
+					return "", err
+				}
+				items[i] = s
+			}
+			return "(" + strings.Join(items, ", ") + ")", nil
+		}
+	default:
+		return "", errors.New("Literal type is not supported by the printer: " + ty.String())
+	}
+	return "", fmt.Errorf("Unexpected literal value: %s: %v", ty.String(), value)
+}
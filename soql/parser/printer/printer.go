@@ -0,0 +1,665 @@
+// Open source implementation of the SOQL parser (Printing phase).
+package printer
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/shellyln/go-nameutil/nameutil"
+	. "github.com/shellyln/go-open-soql-parser/soql/parser/types"
+)
+
+type conditionStackItem struct {
+	text   string
+	opcode SoqlConditionOpcode
+}
+
+func formatObjectName(q *SoqlQuery, object *SoqlObjectInfo, isPrimary bool) string {
+	scope := q
+	if isPrimary {
+		scope = q.Parent
+	}
+
+	name := resolveName(scope, object.Name)
+	if len(name) == 1 && len(object.Name) > 1 && isNameHead(scope, name[0]) {
+		// A single name is resolved as an alias name or an object name in preference to a relationship name.
+		name = object.Name
+	}
+
+	s := formatSymbols(name)
+	if object.AliasName != "" {
+		s += " " + object.AliasName
+	}
+	if object.UsingScope != "" {
+		s += " USING SCOPE " + formatSymbol(object.UsingScope)
+	}
+	if object.Hints != nil {
+		s += " " + formatHints(object.Hints)
+	}
+	return s
+}
+
+func formatHints(hints []SoqlQueryHint) string {
+	items := make([]string, 0, len(hints))
+	for i := 0; i < len(hints); i++ {
+		items = append(items, hints[i].Name+":"+strconv.Quote(hints[i].Value))
+	}
+	return "`" + strings.Join(items, " ") + "`"
+}
+
+func formatSelectField(q *SoqlQuery, field *SoqlFieldInfo) (string, error) {
+	s, err := formatFieldExpression(q, field)
+	if err != nil {
+		return "", err
+	}
+	if field.AliasName != "" && !field.AutoAlias && field.Type != SoqlFieldInfo_FieldSet {
+		s += " " + field.AliasName
+	}
+	if field.Hints != nil {
+		s += " " + formatHints(field.Hints)
+	}
+	return s, nil
+}
+
+func formatFieldReference(q *SoqlQuery, field *SoqlFieldInfo) (string, error) {
+	if field.Type == SoqlFieldInfo_Function && field.AliasName != "" && !field.AutoAlias {
+		// Order by and group by clauses refer to the function by the alias name.
+		return field.AliasName, nil
+	}
+	return formatFieldExpression(q, field)
+}
+
+// Returns the precedence of the arithmetic or concatenation operator.
+// Returns 0 if the field is not an operator.
+func operatorPrecedence(field *SoqlFieldInfo) int {
+	if field.Type != SoqlFieldInfo_Function || len(field.Name) != 1 {
+		return 0
+	}
+	switch field.Name[0] {
+	case "||":
+		if len(field.Parameters) == 2 {
+			return 1
+		}
+	case "+":
+		if len(field.Parameters) == 2 {
+			return 2
+		}
+	case "-":
+		switch len(field.Parameters) {
+		case 1:
+			return 4
+		case 2:
+			return 2
+		}
+	case "*", "/":
+		if len(field.Parameters) == 2 {
+			return 3
+		}
+	}
+	return 0
+}
+
+func formatOperatorExpression(q *SoqlQuery, field *SoqlFieldInfo) (string, error) {
+	prec := operatorPrecedence(field)
+
+	params := make([]string, 0, len(field.Parameters))
+	for i := 0; i < len(field.Parameters); i++ {
+		s, err := formatFieldExpression(q, &field.Parameters[i])
+		if err != nil {
+			return "", err
+		}
+
+		paramPrec := operatorPrecedence(&field.Parameters[i])
+		if paramPrec > 0 {
+			// Left-associative; the right hand side operand of the same precedence needs parentheses.
+			if paramPrec < prec || (paramPrec == prec && i > 0) {
+				s = "(" + s + ")"
+			}
+		}
+		params = append(params, s)
+	}
+
+	if len(params) == 1 {
+		if strings.HasPrefix(params[0], "-") {
+			// Avoid to be a line comment `--`.
+			return "-(" + params[0] + ")", nil
+		}
+		return "-" + params[0], nil
+	}
+	return params[0] + " " + field.Name[0] + " " + params[1], nil
+}
+
+func formatFieldExpression(q *SoqlQuery, field *SoqlFieldInfo) (string, error) {
+	switch field.Type {
+	case SoqlFieldInfo_Field:
+		return formatName(q, field.Name), nil
+	case SoqlFieldInfo_FieldSet:
+		return "FIELDS(" + formatName(q, field.Name) + ")", nil
+	case SoqlFieldInfo_Function:
+		{
+			if len(field.Name) != 1 {
+				return "", errors.New("Function name is not valid: " + strings.Join(field.Name, "."))
+			}
+			if operatorPrecedence(field) > 0 {
+				return formatOperatorExpression(q, field)
+			}
+
+			params := make([]string, 0, len(field.Parameters))
+			for i := 0; i < len(field.Parameters); i++ {
+				s, err := formatFieldExpression(q, &field.Parameters[i])
+				if err != nil {
+					return "", err
+				}
+				params = append(params, s)
+			}
+			return field.Name[0] + "(" + strings.Join(params, ", ") + ")", nil
+		}
+	case SoqlFieldInfo_SubQuery:
+		{
+			if field.SubQuery == nil {
+				return "", errors.New("Subquery is not set")
+			}
+			s, err := formatQuery(field.SubQuery)
+			if err != nil {
+				return "", err
+			}
+			return "(" + s + ")", nil
+		}
+	case SoqlFieldInfo_TypeOf:
+		return formatTypeOf(q, field)
+	case SoqlFieldInfo_ParameterizedValue:
+		if len(field.Name) == 0 {
+			return "", errors.New("Parameter name is not set")
+		}
+		return ":" + field.Name[0], nil
+	case SoqlFieldInfo_DateTimeLiteralName:
+		if v, ok := field.Value.(SoqlDateTimeLiteralName); ok {
+			return formatDateTimeLiteralName(v), nil
+		}
+		if len(field.Name) == 0 {
+			return "", errors.New("Date literal name is not set")
+		}
+		return field.Name[0], nil
+	default:
+		return formatLiteral(field.Type, field.Value)
+	}
+}
+
+func formatTypeOf(q *SoqlQuery, field *SoqlFieldInfo) (string, error) {
+	var sb strings.Builder
+
+	sb.WriteString("TYPEOF ")
+	sb.WriteString(formatName(q, field.Name))
+
+	hasElse := false
+	for i := 0; i < len(field.Branches); i++ {
+		branch := &field.Branches[i]
+
+		if len(branch.Fields) == 0 {
+			return "", errors.New("Typeof branch has no fields: " + strings.Join(field.Name, "."))
+		}
+		fields := make([]string, len(branch.Fields))
+		for j := 0; j < len(branch.Fields); j++ {
+			name := branch.Fields[j].Name
+			if len(name) == 0 {
+				return "", errors.New("Typeof branch field name is not set: " + strings.Join(field.Name, "."))
+			}
+			fields[j] = formatSymbol(name[len(name)-1])
+		}
+
+		if branch.ObjectType == "" {
+			if hasElse || i != len(field.Branches)-1 {
+				return "", errors.New("Typeof else branch should be the last branch: " + strings.Join(field.Name, "."))
+			}
+			hasElse = true
+			sb.WriteString(" ELSE ")
+		} else {
+			sb.WriteString(" WHEN ")
+			sb.WriteString(formatSymbol(branch.ObjectType))
+			sb.WriteString(" THEN ")
+		}
+		sb.WriteString(strings.Join(fields, ", "))
+	}
+
+	sb.WriteString(" END")
+	return sb.String(), nil
+}
+
+func formatConditions(q *SoqlQuery, conditions []SoqlCondition) (string, error) {
+	stack := make([]conditionStackItem, 0, len(conditions))
+
+	for i := 0; i < len(conditions); i++ {
+		cond := &conditions[i]
+
+		switch cond.Opcode {
+		case SoqlConditionOpcode_Noop:
+			// do nothing
+		case SoqlConditionOpcode_Unknown:
+			return "", errors.New("Unknown condition can not be formatted")
+		case SoqlConditionOpcode_FieldInfo:
+			{
+				s, err := formatFieldExpression(q, &cond.Value)
+				if err != nil {
+					return "", err
+				}
+				stack = append(stack, conditionStackItem{
+					text:   s,
+					opcode: cond.Opcode,
+				})
+			}
+		case SoqlConditionOpcode_Not:
+			{
+				if len(stack) < 1 {
+					return "", errors.New("The operand for the unary operator is missing: " + cond.Opcode.String())
+				}
+				op1 := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+
+				text := op1.text
+				switch op1.opcode {
+				case SoqlConditionOpcode_Not, SoqlConditionOpcode_And, SoqlConditionOpcode_Or:
+					text = "(" + text + ")"
+				}
+				stack = append(stack, conditionStackItem{
+					text:   "NOT " + text,
+					opcode: cond.Opcode,
+				})
+			}
+		case SoqlConditionOpcode_And, SoqlConditionOpcode_Or:
+			{
+				if len(stack) < 2 {
+					return "", errors.New("The operand for the binary operator is missing: " + cond.Opcode.String())
+				}
+				op1 := stack[len(stack)-2]
+				op2 := stack[len(stack)-1]
+				stack = stack[:len(stack)-2]
+
+				// NOTE: Mixing 'and' and 'or' without parentheses is not allowed.
+				texts := [2]string{op1.text, op2.text}
+				for j, op := range [2]conditionStackItem{op1, op2} {
+					switch op.opcode {
+					case SoqlConditionOpcode_And, SoqlConditionOpcode_Or:
+						if op.opcode != cond.Opcode {
+							texts[j] = "(" + texts[j] + ")"
+						}
+					}
+				}
+
+				opText := " AND "
+				if cond.Opcode == SoqlConditionOpcode_Or {
+					opText = " OR "
+				}
+				stack = append(stack, conditionStackItem{
+					text:   texts[0] + opText + texts[1],
+					opcode: cond.Opcode,
+				})
+			}
+		default:
+			{
+				if len(stack) < 2 {
+					return "", errors.New("The operand for the binary operator is missing: " + cond.Opcode.String())
+				}
+				op1 := stack[len(stack)-2]
+				op2 := stack[len(stack)-1]
+				stack = stack[:len(stack)-2]
+
+				opText, err := formatConditionalOperator(cond.Opcode)
+				if err != nil {
+					return "", err
+				}
+				stack = append(stack, conditionStackItem{
+					text:   op1.text + " " + opText + " " + op2.text,
+					opcode: cond.Opcode,
+				})
+			}
+		}
+	}
+
+	if len(stack) != 1 {
+		return "", errors.New("Conditional expression is not valid")
+	}
+	return stack[0].text, nil
+}
+
+func formatConditionalOperator(opcode SoqlConditionOpcode) (string, error) {
+	switch opcode {
+	case SoqlConditionOpcode_Eq:
+		return "=", nil
+	case SoqlConditionOpcode_NotEq:
+		return "!=", nil
+	case SoqlConditionOpcode_Lt:
+		return "<", nil
+	case SoqlConditionOpcode_Le:
+		return "<=", nil
+	case SoqlConditionOpcode_Gt:
+		return ">", nil
+	case SoqlConditionOpcode_Ge:
+		return ">=", nil
+	case SoqlConditionOpcode_Like:
+		return "LIKE", nil
+	case SoqlConditionOpcode_NotLike:
+		return "NOT LIKE", nil
+	case SoqlConditionOpcode_In:
+		return "IN", nil
+	case SoqlConditionOpcode_NotIn:
+		return "NOT IN", nil
+	case SoqlConditionOpcode_Includes:
+		return "INCLUDES", nil
+	case SoqlConditionOpcode_Excludes:
+		return "EXCLUDES", nil
+	default:
+		return "", errors.New("Unexpected conditional operator: " + opcode.String())
+	}
+}
+
+func formatDataCategoryFilter(filter *SoqlDataCategoryFilter) (string, error) {
+	var op string
+	switch filter.Operator {
+	case SoqlDataCategoryOperator_At:
+		op = "AT"
+	case SoqlDataCategoryOperator_Above:
+		op = "ABOVE"
+	case SoqlDataCategoryOperator_Below:
+		op = "BELOW"
+	case SoqlDataCategoryOperator_AboveOrBelow:
+		op = "ABOVE_OR_BELOW"
+	default:
+		return "", errors.New("Unexpected data category operator: " + filter.Operator.String())
+	}
+	if len(filter.Categories) == 0 {
+		return "", errors.New("Data category is not specified: " + filter.GroupName)
+	}
+
+	categories := make([]string, len(filter.Categories))
+	for i := 0; i < len(filter.Categories); i++ {
+		categories[i] = formatSymbol(filter.Categories[i])
+	}
+	s := formatSymbol(filter.GroupName) + " " + op + " "
+	if len(categories) == 1 {
+		return s + categories[0], nil
+	}
+	return s + "(" + strings.Join(categories, ", ") + ")", nil
+}
+
+func formatSelectFields(q *SoqlQuery) (string, error) {
+	fields := make([]string, 0, len(q.Fields))
+	for i := 0; i < len(q.Fields); i++ {
+		if q.Fields[i].NotSelected {
+			continue
+		}
+		s, err := formatSelectField(q, &q.Fields[i])
+		if err != nil {
+			return "", err
+		}
+		fields = append(fields, s)
+	}
+	if len(fields) == 0 {
+		return "", errors.New("The select clause has no fields: " + strings.Join(q.From[0].Name, "."))
+	}
+	return strings.Join(fields, ", "), nil
+}
+
+func formatWithClause(w *SoqlWithClause) (string, error) {
+	var sb strings.Builder
+
+	if w.SecurityEnforced {
+		sb.WriteString(" WITH SECURITY_ENFORCED")
+	} else if w.UserMode {
+		sb.WriteString(" WITH USER_MODE")
+	} else if w.SystemMode {
+		sb.WriteString(" WITH SYSTEM_MODE")
+	}
+
+	if len(w.DataCategory) != 0 {
+		filters := make([]string, 0, len(w.DataCategory))
+		for i := 0; i < len(w.DataCategory); i++ {
+			s, err := formatDataCategoryFilter(&w.DataCategory[i])
+			if err != nil {
+				return "", err
+			}
+			filters = append(filters, s)
+		}
+		sb.WriteString(" WITH DATA CATEGORY ")
+		sb.WriteString(strings.Join(filters, " AND "))
+	}
+
+	return sb.String(), nil
+}
+
+func formatOrderByAndOffsetAndLimit(q *SoqlQuery) (string, error) {
+	var sb strings.Builder
+
+	if len(q.OrderBy) != 0 {
+		fields := make([]string, 0, len(q.OrderBy))
+		for i := 0; i < len(q.OrderBy); i++ {
+			s, err := formatFieldReference(q, &q.OrderBy[i].Field)
+			if err != nil {
+				return "", err
+			}
+			if q.OrderBy[i].Desc {
+				s += " DESC"
+			}
+			if q.OrderBy[i].NullsLast {
+				s += " NULLS LAST"
+			}
+			fields = append(fields, s)
+		}
+		sb.WriteString(" ORDER BY ")
+		sb.WriteString(strings.Join(fields, ", "))
+	}
+
+	if q.OffsetAndLimit.LimitParamName != "" {
+		sb.WriteString(" LIMIT :")
+		sb.WriteString(q.OffsetAndLimit.LimitParamName)
+	} else if q.OffsetAndLimit.Limit != 0 {
+		sb.WriteString(" LIMIT ")
+		sb.WriteString(formatInt(q.OffsetAndLimit.Limit))
+	}
+
+	if q.OffsetAndLimit.OffsetParamName != "" {
+		sb.WriteString(" OFFSET :")
+		sb.WriteString(q.OffsetAndLimit.OffsetParamName)
+	} else if q.OffsetAndLimit.Offset != 0 {
+		sb.WriteString(" OFFSET ")
+		sb.WriteString(formatInt(q.OffsetAndLimit.Offset))
+	}
+
+	return sb.String(), nil
+}
+
+func formatQuery(q *SoqlQuery) (string, error) {
+	var sb strings.Builder
+
+	if len(q.From) == 0 {
+		return "", errors.New("The 'from' clause is not set")
+	}
+
+	{
+		s, err := formatSelectFields(q)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString("SELECT ")
+		sb.WriteString(s)
+	}
+
+	{
+		objects := make([]string, 0, len(q.From))
+		for i := 0; i < len(q.From); i++ {
+			if i != 0 && q.From[i].AliasName == "" && q.From[i].UsingScope == "" && q.From[i].Hints == nil {
+				// Relationship objects that have no alias name are implicitly declared by the references.
+				continue
+			}
+			objects = append(objects, formatObjectName(q, &q.From[i], i == 0))
+		}
+
+		sb.WriteString(" FROM ")
+		sb.WriteString(strings.Join(objects, ", "))
+	}
+
+	if len(q.Where) != 0 {
+		s, err := formatConditions(q, q.Where)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(" WHERE ")
+		sb.WriteString(s)
+	}
+
+	{
+		s, err := formatWithClause(&q.With)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(s)
+	}
+
+	if len(q.GroupBy) != 0 {
+		fields := make([]string, 0, len(q.GroupBy))
+		for i := 0; i < len(q.GroupBy); i++ {
+			s, err := formatFieldReference(q, &q.GroupBy[i])
+			if err != nil {
+				return "", err
+			}
+			fields = append(fields, s)
+		}
+		sb.WriteString(" GROUP BY ")
+		switch q.GroupingMode {
+		case SoqlGroupingMode_Rollup:
+			sb.WriteString("ROLLUP(" + strings.Join(fields, ", ") + ")")
+		case SoqlGroupingMode_Cube:
+			sb.WriteString("CUBE(" + strings.Join(fields, ", ") + ")")
+		default:
+			sb.WriteString(strings.Join(fields, ", "))
+		}
+	}
+
+	if len(q.Having) != 0 {
+		s, err := formatConditions(q, q.Having)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(" HAVING ")
+		sb.WriteString(s)
+	}
+
+	{
+		s, err := formatOrderByAndOffsetAndLimit(q)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(s)
+	}
+
+	if q.For.View || q.For.Reference {
+		s := make([]string, 0, 2)
+		if q.For.View {
+			s = append(s, "VIEW")
+		}
+		if q.For.Reference {
+			s = append(s, "REFERENCE")
+		}
+		sb.WriteString(" FOR ")
+		sb.WriteString(strings.Join(s, ", "))
+	} else if q.For.Update {
+		s := make([]string, 0, 2)
+		if q.For.UpdateTracking {
+			s = append(s, "TRACKING")
+		}
+		if q.For.UpdateViewstat {
+			s = append(s, "VIEWSTAT")
+		}
+		sb.WriteString(" FOR UPDATE")
+		if len(s) != 0 {
+			sb.WriteString(" ")
+			sb.WriteString(strings.Join(s, ", "))
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// Serialize the (normalized) query back to the SOQL text.
+// Fields that are not selected (added by normalization) and
+// relationship objects that have no alias name are omitted.
+func Format(q *SoqlQuery) (string, error) {
+	if q == nil {
+		return "", errors.New("Query is nil")
+	}
+	s, err := formatQuery(q)
+	if err != nil {
+		return "", err
+	}
+	if q.AllRows {
+		// The subqueries inherit the flag; It is written only in the primary query.
+		s += " ALL ROWS"
+	}
+	return s, nil
+}
+
+func isNameHead(q *SoqlQuery, s string) bool {
+	for p := q; p != nil; p = p.Parent {
+		for i := 0; i < len(p.From); i++ {
+			if strings.EqualFold(p.From[i].AliasName, s) {
+				return true
+			}
+			if len(p.From[i].Name) == 1 && strings.EqualFold(p.From[i].Name[0], s) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func resolveName(q *SoqlQuery, name []string) []string {
+	nameLen := len(name)
+	if nameLen <= 1 || q == nil {
+		return name
+	}
+
+	ns := name[:nameLen-1]
+
+	if len(q.From) != 0 && nameutil.NameEqualsIgnoreCase(q.From[0].Name, ns) {
+		return name[nameLen-1:]
+	}
+
+	// Find the longest aliased object that qualifies the name.
+	var aliased *SoqlObjectInfo
+	for p := q; p != nil; p = p.Parent {
+		for i := 0; i < len(p.From); i++ {
+			object := &p.From[i]
+			if object.AliasName == "" || (p == q && i == 0) || len(object.Name) > len(ns) {
+				continue
+			}
+			if aliased != nil && len(aliased.Name) >= len(object.Name) {
+				continue
+			}
+			if nameutil.NameEqualsIgnoreCase(object.Name, ns[:len(object.Name)]) {
+				aliased = object
+			}
+		}
+	}
+	if aliased != nil {
+		s := make([]string, 0, nameLen-len(aliased.Name)+1)
+		s = append(s, aliased.AliasName)
+		s = append(s, name[len(aliased.Name):]...)
+		return s
+	}
+
+	if len(q.From) != 0 {
+		primary := q.From[0].Name
+		if len(primary) < nameLen && nameutil.NameEqualsIgnoreCase(primary, name[:len(primary)]) {
+			rel := name[len(primary):]
+			if !isNameHead(q, rel[0]) {
+				return rel
+			}
+		}
+	}
+
+	return name
+}
+
+func formatName(q *SoqlQuery, name []string) string {
+	return formatSymbols(resolveName(q, name))
+}
@@ -0,0 +1,163 @@
+package printer
+
+import (
+	"errors"
+	"strings"
+
+	. "github.com/shellyln/go-open-soql-parser/soql/parser/types"
+)
+
+func formatReturningObject(q *SoqlQuery) (string, error) {
+	if len(q.From) == 0 {
+		return "", errors.New("The returning object is not set")
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString(formatSymbols(q.From[0].Name))
+
+	s, err := formatSelectFields(q)
+	if err != nil {
+		return "", err
+	}
+	sb.WriteString("(")
+	sb.WriteString(s)
+
+	if len(q.Where) != 0 {
+		s, err := formatConditions(q, q.Where)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(" WHERE ")
+		sb.WriteString(s)
+	}
+
+	s, err = formatOrderByAndOffsetAndLimit(q)
+	if err != nil {
+		return "", err
+	}
+	sb.WriteString(s)
+	sb.WriteString(")")
+
+	return sb.String(), nil
+}
+
+func quoteString(s string) string {
+	return "'" + escapeString(s, '\'') + "'"
+}
+
+func formatStringList(s []string) string {
+	items := make([]string, len(s))
+	for i := 0; i < len(s); i++ {
+		items[i] = quoteString(s[i])
+	}
+	return strings.Join(items, ", ")
+}
+
+func formatSoslWithClause(w *SoslWithClause) (string, error) {
+	var sb strings.Builder
+
+	if w.Division != "" {
+		sb.WriteString(" WITH DIVISION = " + quoteString(w.Division))
+	}
+
+	s, err := formatWithClause(&w.SoqlWithClause)
+	if err != nil {
+		return "", err
+	}
+	sb.WriteString(s)
+
+	if w.Highlight {
+		sb.WriteString(" WITH HIGHLIGHT")
+	}
+	if w.Snippet {
+		sb.WriteString(" WITH SNIPPET")
+		if w.SnippetTargetLength != 0 {
+			sb.WriteString("(target_length=" + formatInt(w.SnippetTargetLength) + ")")
+		}
+	}
+	if len(w.Network) == 1 {
+		sb.WriteString(" WITH NETWORK = " + quoteString(w.Network[0]))
+	} else if len(w.Network) != 0 {
+		sb.WriteString(" WITH NETWORK IN (" + formatStringList(w.Network) + ")")
+	}
+	if w.PricebookId != "" {
+		sb.WriteString(" WITH PRICEBOOKID = " + quoteString(w.PricebookId))
+	}
+	if w.Metadata != "" {
+		sb.WriteString(" WITH METADATA = " + quoteString(w.Metadata))
+	}
+	if w.NoSpellCorrection {
+		sb.WriteString(" WITH SPELL_CORRECTION = false")
+	}
+
+	return sb.String(), nil
+}
+
+func formatSosl(q *SoslQuery) (string, error) {
+	var sb strings.Builder
+
+	sb.WriteString("FIND {")
+	sb.WriteString(q.SearchQuery) // The search query keeps the escape sequences.
+	sb.WriteString("}")
+
+	switch q.SearchGroup {
+	case SoslSearchGroup_NameFields:
+		sb.WriteString(" IN NAME FIELDS")
+	case SoslSearchGroup_EmailFields:
+		sb.WriteString(" IN EMAIL FIELDS")
+	case SoslSearchGroup_PhoneFields:
+		sb.WriteString(" IN PHONE FIELDS")
+	case SoslSearchGroup_SidebarFields:
+		sb.WriteString(" IN SIDEBAR FIELDS")
+	}
+
+	if len(q.Returning) != 0 {
+		objects := make([]string, 0, len(q.Returning))
+		for i := 0; i < len(q.Returning); i++ {
+			s, err := formatReturningObject(&q.Returning[i])
+			if err != nil {
+				return "", err
+			}
+			objects = append(objects, s)
+		}
+		sb.WriteString(" RETURNING ")
+		sb.WriteString(strings.Join(objects, ", "))
+	}
+
+	s, err := formatSoslWithClause(&q.With)
+	if err != nil {
+		return "", err
+	}
+	sb.WriteString(s)
+
+	if q.LimitParamName != "" {
+		sb.WriteString(" LIMIT :")
+		sb.WriteString(q.LimitParamName)
+	} else if q.Limit != 0 {
+		sb.WriteString(" LIMIT ")
+		sb.WriteString(formatInt(q.Limit))
+	}
+
+	if q.Update.Tracking || q.Update.Viewstat {
+		s := make([]string, 0, 2)
+		if q.Update.Tracking {
+			s = append(s, "TRACKING")
+		}
+		if q.Update.Viewstat {
+			s = append(s, "VIEWSTAT")
+		}
+		sb.WriteString(" UPDATE ")
+		sb.WriteString(strings.Join(s, ", "))
+	}
+
+	return sb.String(), nil
+}
+
+// Serialize the (normalized) SOSL query back to the SOSL text.
+func FormatSosl(q *SoslQuery) (string, error) {
+	if q == nil {
+		return "", errors.New("Query is nil")
+	}
+	return formatSosl(q)
+}
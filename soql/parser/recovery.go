@@ -0,0 +1,151 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/shellyln/go-open-soql-parser/soql/parser/core"
+	"github.com/shellyln/go-open-soql-parser/soql/parser/types"
+	. "github.com/shellyln/takenoco/base"
+	. "github.com/shellyln/takenoco/string"
+)
+
+const maxRecoveryAttempts = 256
+
+func blankOut(src []byte, start, end int) {
+	for i := start; i < end && i < len(src); i++ {
+		if src[i] != '\r' && src[i] != '\n' {
+			src[i] = ' '
+		}
+	}
+}
+
+// Extend the end of the span to the closing parentheses of the opening parentheses in the span.
+// If the span is extended, it is also extended to the next synchronization point.
+func closeParens(s string, points []int, start, end int) int {
+	depth := 0
+	for i := start; i < end; i++ {
+		if s[i] == '(' {
+			depth++
+		}
+	}
+	if depth == 0 {
+		return end
+	}
+	for _, pt := range points {
+		if depth <= 0 {
+			break
+		}
+		if pt >= end && s[pt] == ')' {
+			depth--
+			end = pt + 1
+		}
+	}
+	for _, pt := range points {
+		if pt >= end {
+			return pt
+		}
+	}
+	return len(s)
+}
+
+// If the logical operator is at the end of the span, the span is extended to include it
+// (e.g. "a = = 1 AND" of "WHERE a = = 1 AND b = 2").
+func skipLogicalSyncPoint(s string, end int) int {
+	if end < len(s) && s[end] != ',' && s[end] != ')' && !core.IsClauseSyncPoint(s, end) {
+		return core.SyncPointEnd(s, end)
+	}
+	return end
+}
+
+// Parse the source in the error recovery mode.
+// On the syntax error, the span from the error position to the next synchronization point
+// is blanked out and the source is parsed again.
+// The synchronizing closing parentheses are never blanked out, so the subqueries are kept balanced.
+// If the next error is a consequence of the blanked span (it is not ahead of the span),
+// it is not reported and the span is extended back to the previous synchronization point.
+// The span is never extended back past the clause keyword, so the other clauses are kept.
+// The offsets are not changed by blanking out, so the errors point to the original source.
+func recoverParse(p ParserFn, s string, opts *core.ParserOptions) (ParserContext, types.ParseErrorList) {
+	src := []byte(s)
+	errs := make(types.ParseErrorList, 0)
+	blankStart, blankEnd := -1, -1
+
+	for n := 0; n < maxRecoveryAttempts; n++ {
+		cur := string(src)
+
+		out, err := p(*NewStringParserContextWithTag(cur, opts))
+		perr := checkParseResult(s, out, err)
+		if perr == nil {
+			return out, errs
+		}
+
+		pos := perr.(*types.ParseError).Offset
+		points := core.SyncPoints(cur)
+
+		if pos > blankEnd {
+			errs = append(errs, perr.(*types.ParseError))
+
+			blankStart, blankEnd = pos, len(cur)
+			for _, pt := range points {
+				// The error is in the middle of the clause keyword (e.g. "ORDER BY" without the fields),
+				// or just after the clause keyword (e.g. "GROUP Name"); The keyword is also blanked out.
+				if pt < pos {
+					if end := core.SyncPointEnd(cur, pt); pos < end ||
+						core.IsClauseSyncPoint(cur, pt) && strings.TrimSpace(cur[end:pos]) == "" {
+						blankStart = pt
+					}
+				}
+				// The closing parenthesis is not blanked out even if the error is on it.
+				if pt > pos || (pt == pos && cur[pt] != ',') {
+					blankEnd = pt
+					if cur[pt] == ',' {
+						blankEnd++
+					}
+					break
+				}
+			}
+			blankEnd = closeParens(cur, points, blankStart, blankEnd)
+		} else {
+			// The clause keyword is already blanked out; Never go back past it.
+			if blankStart <= 0 || (blankStart < blankEnd && core.IsClauseSyncPoint(s, blankStart)) {
+				break
+			}
+			prev := -1
+			for _, pt := range points {
+				if pt >= blankStart {
+					break
+				}
+				prev = pt
+			}
+
+			paren := core.UnclosedParen(cur, prev+1, blankStart)
+
+			switch {
+			case paren >= 0 && paren+1 < blankStart:
+				// Keep the opening parenthesis and blank out the inside.
+				blankStart = paren + 1
+				blankEnd = skipLogicalSyncPoint(cur, blankEnd)
+			case prev < 0:
+				blankStart = 0
+			case core.IsClauseSyncPoint(cur, prev):
+				if end := core.SyncPointEnd(cur, prev); end < blankStart {
+					// Keep the clause keyword and blank out its body.
+					blankStart = end
+					blankEnd = skipLogicalSyncPoint(cur, blankEnd)
+				} else {
+					blankStart = prev
+				}
+			case cur[prev] == ')':
+				blankStart = prev + 1
+			default:
+				// Comma or logical operator
+				blankStart = prev
+			}
+			blankEnd = closeParens(cur, points, blankStart, blankEnd)
+		}
+
+		blankOut(src, blankStart, blankEnd)
+	}
+
+	return ParserContext{MatchStatus: MatchStatus_Unmatched}, errs
+}
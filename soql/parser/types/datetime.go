@@ -0,0 +1,144 @@
+package types
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Parse the DateTime literal (e.g. 2023-01-02T03:04:05.123+09:00 , -0001-01-01T00:00:00Z , +10000-01-01T00:00Z)
+// or the Time literal (e.g. 03:04:05.123 , 03:04:05Z , 03:04+09:00).
+// The year of the DateTime literal has 4 or more digits and may have a sign.
+// The offset is required for the DateTime literal and is optional for the Time literal.
+func ParseSoqlDateTimeValue(s string) (SoqlDateTimeValue, error) {
+	v := SoqlDateTimeValue{Source: s}
+	src := s
+
+	year, month, day := 1970, 1, 1
+
+	if i := strings.IndexByte(s, 'T'); i >= 0 {
+		date := s[:i]
+		s = s[i+1:]
+
+		sign := 1
+		if date != "" && (date[0] == '+' || date[0] == '-') {
+			if date[0] == '-' {
+				sign = -1
+			}
+			date = date[1:]
+		}
+
+		parts := strings.Split(date, "-")
+		if len(parts) != 3 || len(parts[0]) < 4 || len(parts[1]) != 2 || len(parts[2]) != 2 {
+			return v, errors.New("Bad datetime format: " + src)
+		}
+
+		var ok bool
+		if year, ok = parseDateTimeDigits(parts[0]); !ok {
+			return v, errors.New("Bad datetime format: " + src)
+		}
+		year *= sign
+
+		if month, ok = parseDateTimeDigits(parts[1]); !ok || month < 1 || month > 12 {
+			return v, errors.New("Bad datetime format (month): " + src)
+		}
+		if day, ok = parseDateTimeDigits(parts[2]); !ok || day < 1 ||
+			day > time.Date(year, time.Month(month)+1, 0, 0, 0, 0, 0, time.UTC).Day() {
+
+			return v, errors.New("Bad datetime format (day): " + src)
+		}
+	}
+
+	loc := time.UTC
+	switch {
+	case strings.HasSuffix(s, "Z"):
+		s = s[:len(s)-1]
+		v.HasOffset = true
+	case len(s) > 6 && (s[len(s)-6] == '+' || s[len(s)-6] == '-') && s[len(s)-3] == ':':
+		offset := s[len(s)-6:]
+		s = s[:len(s)-6]
+
+		hh, ok1 := parseDateTimeDigits(offset[1:3])
+		mm, ok2 := parseDateTimeDigits(offset[4:6])
+		if !ok1 || !ok2 || hh > 23 || mm > 59 {
+			return v, errors.New("Bad datetime format (offset): " + src)
+		}
+
+		sec := hh*3600 + mm*60
+		if offset[0] == '-' {
+			sec = -sec
+		}
+		loc = time.FixedZone("", sec)
+		v.HasOffset = true
+	}
+
+	if strings.IndexByte(src, 'T') >= 0 && !v.HasOffset {
+		return v, errors.New("Bad datetime format (offset is required): " + src)
+	}
+
+	nsec := 0
+	hasFraction := false
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		hasFraction = true
+		frac := s[i+1:]
+		s = s[:i]
+
+		var ok bool
+		if len(frac) < 1 || len(frac) > 9 {
+			return v, errors.New("Bad datetime format (fraction): " + src)
+		}
+		if nsec, ok = parseDateTimeDigits((frac + "000000000")[0:9]); !ok {
+			return v, errors.New("Bad datetime format (fraction): " + src)
+		}
+	}
+
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 || len(parts) > 3 || (hasFraction && len(parts) != 3) {
+		return v, errors.New("Bad datetime format: " + src)
+	}
+
+	items := [3]int{}
+	for i := 0; i < len(parts); i++ {
+		var ok bool
+		if len(parts[i]) != 2 {
+			return v, errors.New("Bad datetime format: " + src)
+		}
+		if items[i], ok = parseDateTimeDigits(parts[i]); !ok {
+			return v, errors.New("Bad datetime format: " + src)
+		}
+	}
+	if items[0] > 23 || items[1] > 59 || items[2] > 59 {
+		return v, errors.New("Bad datetime format (time): " + src)
+	}
+
+	v.Time = time.Date(year, time.Month(month), day, items[0], items[1], items[2], nsec, loc)
+
+	return v, nil
+}
+
+func parseDateTimeDigits(s string) (int, bool) {
+	if s == "" {
+		return 0, false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return 0, false
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// Returns the original representation of the literal.
+func (v SoqlDateTimeValue) String() string {
+	return v.Source
+}
+
+// Returns the instant in UTC.
+func (v SoqlDateTimeValue) UTC() time.Time {
+	return v.Time.UTC()
+}
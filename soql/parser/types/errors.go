@@ -0,0 +1,76 @@
+package types
+
+import (
+	"strconv"
+	"strings"
+)
+
+type ParseErrorCode int
+
+const (
+	ParseErrorCode_Syntax                 ParseErrorCode = iota + 1 // The grammar detected an invalid token.
+	ParseErrorCode_Unmatched                                        // The source did not match the grammar.
+	ParseErrorCode_Normalize                                        // The query is syntactically valid but it is rejected by the postprocessing phase.
+	parseErrorCode_EndOfConstDefinitions_                           // For UnmarshalJSON (internal use)
+)
+
+func (t ParseErrorCode) String() string {
+	switch t {
+	case ParseErrorCode_Syntax:
+		return "Syntax"
+	case ParseErrorCode_Unmatched:
+		return "Unmatched"
+	case ParseErrorCode_Normalize:
+		return "Normalize"
+	default:
+		return "Undefined"
+	}
+}
+
+// Error of the parser and the postprocessing phase.
+// Offsets are 0-based byte offsets; Lines and columns are 1-based (columns are counted in bytes).
+type ParseError struct {
+	Code      ParseErrorCode `json:"code"`              // Error code
+	Message   string         `json:"message"`           // Error message without position
+	Offset    int            `json:"offset"`            // Start offset of the error span
+	Line      int            `json:"line"`              // Start line of the error span
+	Col       int            `json:"col"`               // Start column of the error span
+	EndOffset int            `json:"endOffset"`         // End offset of the error span (exclusive)
+	EndLine   int            `json:"endLine"`           // End line of the error span
+	EndCol    int            `json:"endCol"`            // End column of the error span (exclusive)
+	Excerpt   string         `json:"excerpt,omitempty"` // Source excerpt that points to the error; Empty if the span is the whole source.
+	Err       error          `json:"-"`                 // Underlying error
+}
+
+func (e *ParseError) Error() string {
+	if e.Excerpt == "" {
+		return e.Message
+	}
+	return e.Message +
+		"\n --> Line " + strconv.Itoa(e.Line) +
+		", Col " + strconv.Itoa(e.Col) + "\n" +
+		e.Excerpt
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Errors collected by the error recovery mode.
+type ParseErrorList []*ParseError
+
+func (l ParseErrorList) Error() string {
+	s := make([]string, len(l))
+	for i := 0; i < len(l); i++ {
+		s[i] = l[i].Error()
+	}
+	return strings.Join(s, "\n")
+}
+
+func (l ParseErrorList) Unwrap() []error {
+	z := make([]error, len(l))
+	for i := 0; i < len(l); i++ {
+		z[i] = l[i]
+	}
+	return z
+}
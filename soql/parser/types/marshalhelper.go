@@ -25,6 +25,22 @@ func unmarshalSoqlFieldInfoValue(b json.RawMessage, typ SoqlFieldInfoType) (inte
 			}
 			return v, nil
 		}
+	case SoqlFieldInfo_Literal_Decimal:
+		{
+			var v SoqlDecimal
+			if err := json.Unmarshal(b, &v); err != nil {
+				return nil, err
+			}
+			return v, nil
+		}
+	case SoqlFieldInfo_Literal_Currency:
+		{
+			var v SoqlCurrency
+			if err := json.Unmarshal(b, &v); err != nil {
+				return nil, err
+			}
+			return v, nil
+		}
 	case SoqlFieldInfo_Literal_Bool:
 		{
 			var v bool
@@ -49,9 +65,7 @@ func unmarshalSoqlFieldInfoValue(b json.RawMessage, typ SoqlFieldInfoType) (inte
 			}
 			return v, nil
 		}
-	case SoqlFieldInfo_Literal_Date,
-		SoqlFieldInfo_Literal_DateTime,
-		SoqlFieldInfo_Literal_Time:
+	case SoqlFieldInfo_Literal_Date:
 		{
 			var v time.Time
 			if err := json.Unmarshal(b, &v); err != nil {
@@ -59,6 +73,15 @@ func unmarshalSoqlFieldInfoValue(b json.RawMessage, typ SoqlFieldInfoType) (inte
 			}
 			return v, nil
 		}
+	case SoqlFieldInfo_Literal_DateTime,
+		SoqlFieldInfo_Literal_Time:
+		{
+			var v SoqlDateTimeValue
+			if err := json.Unmarshal(b, &v); err != nil {
+				return nil, err
+			}
+			return v, nil
+		}
 	case SoqlFieldInfo_Literal_DateTimeRange:
 		{
 			var v SoqlTimeRange
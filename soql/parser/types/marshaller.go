@@ -28,8 +28,10 @@ func (t *SoqlFieldInfo) UnmarshalJSON(b []byte) error {
 	t.Type = t2.Type
 	t.Name = t2.Name
 	t.AliasName = t2.AliasName
+	t.AutoAlias = t2.AutoAlias
 	t.Parameters = t2.Parameters
 	t.SubQuery = t2.SubQuery
+	t.Branches = t2.Branches
 	t.NotSelected = t2.NotSelected
 	t.Aggregated = t2.Aggregated
 	t.Hints = t2.Hints
@@ -37,6 +39,7 @@ func (t *SoqlFieldInfo) UnmarshalJSON(b []byte) error {
 	t.ColIndex = t2.ColIndex
 	t.ViewId = t2.ViewId
 	t.Key = t2.Key
+	t.Span = t2.Span
 
 	if v, err := unmarshalSoqlFieldInfoValue(t2.Value, t2.Type); err != nil {
 		return err
@@ -79,17 +82,96 @@ func (t *SoqlConditionOpcode) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+func (t SoqlGroupingMode) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + t.String() + `"`), nil
+}
+
+func (t *SoqlGroupingMode) UnmarshalJSON(b []byte) error {
+	s := string(b)
+	for i := SoqlGroupingMode(0); i < soqlGroupingMode_EndOfConstDefinitions_; i++ {
+		if `"`+i.String()+`"` == s {
+			*t = i
+			break
+		}
+	}
+	return nil
+}
+
+func (t SoqlDataCategoryOperator) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + t.String() + `"`), nil
+}
+
+func (t *SoqlDataCategoryOperator) UnmarshalJSON(b []byte) error {
+	s := string(b)
+	for i := SoqlDataCategoryOperator(0); i < soqlDataCategoryOperator_EndOfConstDefinitions_; i++ {
+		if `"`+i.String()+`"` == s {
+			*t = i
+			break
+		}
+	}
+	return nil
+}
+
 func (t *SoqlCondition) MarshalJSON() ([]byte, error) {
 	if t.Opcode == SoqlConditionOpcode_FieldInfo {
 		t2 := soqlCondition_marshalAll{
 			Opcode: t.Opcode,
 			Value:  t.Value,
+			Span:   t.Span,
 		}
 		return json.Marshal(t2)
 	} else {
 		t2 := soqlCondition_marshalOp{
 			Opcode: t.Opcode,
+			Span:   t.Span,
 		}
 		return json.Marshal(t2)
 	}
 }
+
+func (v SoqlDateTimeValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.Source)
+}
+
+func (v *SoqlDateTimeValue) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	t, err := ParseSoqlDateTimeValue(s)
+	if err != nil {
+		return err
+	}
+	*v = t
+	return nil
+}
+
+func (t SoslSearchGroup) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + t.String() + `"`), nil
+}
+
+func (t *SoslSearchGroup) UnmarshalJSON(b []byte) error {
+	s := string(b)
+	for i := SoslSearchGroup(0); i < soslSearchGroup_EndOfConstDefinitions_; i++ {
+		if `"`+i.String()+`"` == s {
+			*t = i
+			break
+		}
+	}
+	return nil
+}
+
+func (t ParseErrorCode) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + t.String() + `"`), nil
+}
+
+func (t *ParseErrorCode) UnmarshalJSON(b []byte) error {
+	s := string(b)
+	for i := ParseErrorCode(0); i < parseErrorCode_EndOfConstDefinitions_; i++ {
+		if `"`+i.String()+`"` == s {
+			*t = i
+			break
+		}
+	}
+	return nil
+}
@@ -2,6 +2,8 @@ package types
 
 import (
 	"encoding/json"
+	"math/big"
+	"strconv"
 	"time"
 )
 
@@ -20,12 +22,15 @@ const (
 	SoqlFieldInfo_Literal_String                                      // string
 	SoqlFieldInfo_Literal_Blob                                        // []byte
 	SoqlFieldInfo_Literal_Date                                        // timer.Time
-	SoqlFieldInfo_Literal_DateTime                                    // timer.Time
-	SoqlFieldInfo_Literal_Time                                        // timer.Time
+	SoqlFieldInfo_Literal_DateTime                                    // SoqlDateTimeValue
+	SoqlFieldInfo_Literal_Time                                        // SoqlDateTimeValue
 	SoqlFieldInfo_Literal_DateTimeRange                               // SoqlTimeRange
 	SoqlFieldInfo_Literal_List                                        // []SoqlListItem
 	SoqlFieldInfo_ParameterizedValue                                  // string
 	SoqlFieldInfo_DateTimeLiteralName                                 // SoqlDateTimeLiteralName
+	SoqlFieldInfo_TypeOf                                              // polymorphic relationship name and branches
+	SoqlFieldInfo_Literal_Decimal                                     // SoqlDecimal
+	SoqlFieldInfo_Literal_Currency                                    // SoqlCurrency
 	SoqlFieldInfo_EndOfConstDefinitions_                              // For UnmarshalJSON (internal use)
 )
 
@@ -65,6 +70,12 @@ func (t SoqlFieldInfoType) String() string {
 		return "ParameterizedValue"
 	case SoqlFieldInfo_DateTimeLiteralName:
 		return "DateTimeLiteralName"
+	case SoqlFieldInfo_TypeOf:
+		return "TypeOf"
+	case SoqlFieldInfo_Literal_Decimal:
+		return "Decimal"
+	case SoqlFieldInfo_Literal_Currency:
+		return "Currency"
 	default:
 		return "Undefined"
 	}
@@ -75,38 +86,62 @@ type SoqlQueryHint struct {
 	Value string `json:"value,omitempty"`
 }
 
+// Source span of the node.
+// Offsets are 0-based byte offsets; Lines and columns are 1-based (columns are counted in bytes).
+type SoqlSourceSpan struct {
+	Offset    int `json:"offset"`    // Start offset
+	Line      int `json:"line"`      // Start line
+	Col       int `json:"col"`       // Start column
+	EndOffset int `json:"endOffset"` // End offset (exclusive)
+	EndLine   int `json:"endLine"`   // End line
+	EndCol    int `json:"endCol"`    // End column (exclusive)
+}
+
 // NOTE: When adding items, also add them to soqlFieldInfo_unmarshal and UnmarshalJSON.
 type SoqlFieldInfo struct {
-	Type        SoqlFieldInfoType `json:"type,omitempty"`
-	ClassName   string            `json:"-"`                     // (internal use) Used by parser
-	Name        []string          `json:"name,omitempty"`        // for Field, FieldSet, Function, ParameterizedValue, DateTimeLiteralName
-	Value       interface{}       `json:"value,omitempty"`       // for Literal_*
-	AliasName   string            `json:"aliasName,omitempty"`   // for all (optional)
-	Parameters  []SoqlFieldInfo   `json:"parameters,omitempty"`  // for Function; It will be null within the filter, sort, select conditions in the execution plan.
-	SubQuery    *SoqlQuery        `json:"subQuery,omitempty"`    // for SubQuery; It will be null within the filter, sort, select conditions in the execution plan.
-	NotSelected bool              `json:"notSelected,omitempty"` // It appears only in parameters and conditional expressions.
-	Aggregated  bool              `json:"aggregated,omitempty"`  // It is an aggregation function result field or not
-	Hints       []SoqlQueryHint   `json:"hints,omitempty"`       // TODO: hints
-	ColumnId    int               `json:"columnId,omitempty"`    // Column unique id; 1-based; If 0, it is not set.; Unique column Id across all main and sub queries
-	ColIndex    int               `json:"colIndex"`              // Column index in the object
-	ViewId      int               `json:"viewId,omitempty"`      // View (table/object) unique id; 1-based; If 0, it is not set.
-	Key         string            `json:"key,omitempty"`         // (internal use) Base64-encoded, dot-delimited Name field value
+	Type        SoqlFieldInfoType  `json:"type,omitempty"`
+	ClassName   string             `json:"-"`                     // (internal use) Used by parser
+	Name        []string           `json:"name,omitempty"`        // for Field, FieldSet, Function, ParameterizedValue, DateTimeLiteralName, TypeOf
+	Value       interface{}        `json:"value,omitempty"`       // for Literal_*
+	AliasName   string             `json:"aliasName,omitempty"`   // for all (optional)
+	AutoAlias   bool               `json:"autoAlias,omitempty"`   // The alias name (e.g. expr0) is not in the source; It is assigned by the normalization.
+	Parameters  []SoqlFieldInfo    `json:"parameters,omitempty"`  // for Function; It will be null within the filter, sort, select conditions in the execution plan.
+	SubQuery    *SoqlQuery         `json:"subQuery,omitempty"`    // for SubQuery; It will be null within the filter, sort, select conditions in the execution plan.
+	Branches    []SoqlTypeOfBranch `json:"branches,omitempty"`    // for TypeOf; `when` and `else` branches
+	NotSelected bool               `json:"notSelected,omitempty"` // It appears only in parameters and conditional expressions.
+	Aggregated  bool               `json:"aggregated,omitempty"`  // It is an aggregation function result field or not
+	Hints       []SoqlQueryHint    `json:"hints,omitempty"`       // Hinting string (e.g. `type:"id"`) for the data adapter
+	ColumnId    int                `json:"columnId,omitempty"`    // Column unique id; 1-based; If 0, it is not set.; Unique column Id across all main and sub queries
+	ColIndex    int                `json:"colIndex"`              // Column index in the object
+	ViewId      int                `json:"viewId,omitempty"`      // View (table/object) unique id; 1-based; If 0, it is not set.
+	Key         string             `json:"key,omitempty"`         // (internal use) Base64-encoded, dot-delimited Name field value
+	Span        *SoqlSourceSpan    `json:"span,omitempty"`        // Source span; If the field is synthesized by the normalization, it points to the reference that caused it.
 }
 
 type soqlFieldInfo_unmarshal struct {
-	Type        SoqlFieldInfoType `json:"type,omitempty"`
-	Name        []string          `json:"name,omitempty"`
-	Value       json.RawMessage   `json:"value"`
-	AliasName   string            `json:"aliasName,omitempty"`
-	Parameters  []SoqlFieldInfo   `json:"parameters,omitempty"`
-	SubQuery    *SoqlQuery        `json:"subQuery,omitempty"`
-	NotSelected bool              `json:"notSelected,omitempty"`
-	Aggregated  bool              `json:"Aggregated,omitempty"`
-	Hints       []SoqlQueryHint   `json:"hints,omitempty"`
-	ColumnId    int               `json:"columnId,omitempty"`
-	ColIndex    int               `json:"colIndex"`
-	ViewId      int               `json:"viewId,omitempty"`
-	Key         string            `json:"key,omitempty"`
+	Type        SoqlFieldInfoType  `json:"type,omitempty"`
+	Name        []string           `json:"name,omitempty"`
+	Value       json.RawMessage    `json:"value"`
+	AliasName   string             `json:"aliasName,omitempty"`
+	AutoAlias   bool               `json:"autoAlias,omitempty"`
+	Parameters  []SoqlFieldInfo    `json:"parameters,omitempty"`
+	SubQuery    *SoqlQuery         `json:"subQuery,omitempty"`
+	Branches    []SoqlTypeOfBranch `json:"branches,omitempty"`
+	NotSelected bool               `json:"notSelected,omitempty"`
+	Aggregated  bool               `json:"Aggregated,omitempty"`
+	Hints       []SoqlQueryHint    `json:"hints,omitempty"`
+	ColumnId    int                `json:"columnId,omitempty"`
+	ColIndex    int                `json:"colIndex"`
+	ViewId      int                `json:"viewId,omitempty"`
+	Key         string             `json:"key,omitempty"`
+	Span        *SoqlSourceSpan    `json:"span,omitempty"`
+}
+
+type SoqlTypeOfBranch struct {
+	ObjectType string          `json:"objectType,omitempty"` // Object type name of the `when` branch; If empty, it is the `else` branch.
+	Fields     []SoqlFieldInfo `json:"fields,omitempty"`     // Fields selected if the polymorphic relationship refers to the object type
+	ViewId     int             `json:"viewId,omitempty"`     // View (table/object) unique id of the branch; 1-based; If 0, it is not set.
+	Key        string          `json:"key,omitempty"`        // (internal use) Key of the object of the branch
 }
 
 type SoqlListItem struct {
@@ -119,6 +154,34 @@ type SoqlDateTimeLiteralName struct {
 	N    int    `json:"n,omitempty"`
 }
 
+// Arbitrary-precision decimal number.
+// It keeps the exact digits of the literal (e.g. "12345678901234567.89").
+type SoqlDecimal string
+
+// Returns the exact value of the decimal number.
+func (d SoqlDecimal) Rat() (*big.Rat, bool) {
+	return new(big.Rat).SetString(string(d))
+}
+
+// Returns the nearest float64 value of the decimal number.
+func (d SoqlDecimal) Float64() (float64, error) {
+	return strconv.ParseFloat(string(d), 64)
+}
+
+// Currency literal (e.g. USD5000, EUR100.50)
+type SoqlCurrency struct {
+	Code  string      `json:"code,omitempty"`  // ISO currency code (e.g. USD)
+	Value SoqlDecimal `json:"value,omitempty"` // Amount; It keeps the exact digits of the literal.
+}
+
+// DateTime and Time literal value.
+// It keeps the original offset and the original representation of the literal.
+type SoqlDateTimeValue struct {
+	Time      time.Time // Instant in the fixed zone of the original offset; The year may be out of the range [0, 9999].
+	HasOffset bool      // If false, the Time literal has no offset (UTC is assumed).
+	Source    string    // Original representation of the literal (e.g. "2023-01-02T03:04:05.123+09:00")
+}
+
 type SoqlTimeRange struct {
 	Start time.Time
 	End   time.Time
@@ -126,15 +189,19 @@ type SoqlTimeRange struct {
 
 // Object (table)
 type SoqlObjectInfo struct {
-	Name           []string        `json:"name,omitempty"`          // Object (table) or relationship name with namespace (object graph path)
-	AliasName      string          `json:"aliasName,omitempty"`     // Alias name
-	HasConditions  bool            `json:"hasConditions,omitempty"` // Query has conditions originally. If false and this object is on the right side, prevent performing an inner join.
-	InnerJoin      bool            `json:"innerJoin,omitempty"`     // When this object is on the left side, an inner join is performed.
-	Hints          []SoqlQueryHint `json:"hints,omitempty"`         // TODO: hints
-	PerObjectQuery *SoqlQuery      `json:"perObjectQuery"`          // A query that extracts only the filter and sort conditions and fields related to this object. A simple query, not including function calls, etc.
-	ViewId         int             `json:"viewId,omitempty"`        // View (table/object) unique id; 1-based; If 0, it is not set.
-	ParentViewId   int             `json:"parentViewId,omitempty"`  // View id of parent (left side on joining) relationship object.
-	Key            string          `json:"key,omitempty"`           // (internal use) Base64-encoded, dot-delimited Name field value
+	Name                  []string        `json:"name,omitempty"`                  // Object (table) or relationship name with namespace (object graph path)
+	AliasName             string          `json:"aliasName,omitempty"`             // Alias name
+	HasConditions         bool            `json:"hasConditions,omitempty"`         // Query has conditions originally. If false and this object is on the right side, prevent performing an inner join.
+	InnerJoin             bool            `json:"innerJoin,omitempty"`             // When this object is on the left side, an inner join is performed.
+	UsingScope            string          `json:"usingScope,omitempty"`            // Filter scope name of the `using scope` clause (e.g. Everything, Mine, Team)
+	PolymorphicType       string          `json:"polymorphicType,omitempty"`       // Object type of the polymorphic relationship (`typeof` ... `when` branch); It is the discriminator of the view.
+	PolymorphicTypeFilter []string        `json:"polymorphicTypeFilter,omitempty"` // Object types restricted by the `Type` conditions on the polymorphic relationship (e.g. What.Type = 'Account')
+	Hints                 []SoqlQueryHint `json:"hints,omitempty"`                 // Hinting string (e.g. `uri:"contact.csv"`) for the data adapter
+	PerObjectQuery        *SoqlQuery      `json:"perObjectQuery"`                  // A query that extracts only the filter and sort conditions and fields related to this object. A simple query, not including function calls, etc.
+	ViewId                int             `json:"viewId,omitempty"`                // View (table/object) unique id; 1-based; If 0, it is not set.
+	ParentViewId          int             `json:"parentViewId,omitempty"`          // View id of parent (left side on joining) relationship object.
+	Key                   string          `json:"key,omitempty"`                   // (internal use) Base64-encoded, dot-delimited Name field value
+	Span                  *SoqlSourceSpan `json:"span,omitempty"`                  // Source span; If the object is implicitly declared by the normalization, it points to the reference that caused it.
 }
 
 type SoqlConditionOpcode int
@@ -207,21 +274,25 @@ func (t SoqlConditionOpcode) String() string {
 type SoqlCondition struct {
 	Opcode SoqlConditionOpcode `json:"opcode,omitempty"`
 	Value  SoqlFieldInfo       `json:"value,omitempty"`
+	Span   *SoqlSourceSpan     `json:"span,omitempty"` // Source span of the operand or the (sub)expression of the operator
 }
 
 type soqlCondition_marshalAll struct {
 	Opcode SoqlConditionOpcode `json:"opcode,omitempty"`
 	Value  SoqlFieldInfo       `json:"value,omitempty"`
+	Span   *SoqlSourceSpan     `json:"span,omitempty"`
 }
 
 type soqlCondition_marshalOp struct {
 	Opcode SoqlConditionOpcode `json:"opcode,omitempty"`
+	Span   *SoqlSourceSpan     `json:"span,omitempty"`
 }
 
 type SoqlOrderByInfo struct {
-	Field     SoqlFieldInfo `json:"field,omitempty"`
-	Desc      bool          `json:"desc,omitempty"`
-	NullsLast bool          `json:"nullsLast,omitempty"`
+	Field     SoqlFieldInfo   `json:"field,omitempty"`
+	Desc      bool            `json:"desc,omitempty"`
+	NullsLast bool            `json:"nullsLast,omitempty"`
+	Span      *SoqlSourceSpan `json:"span,omitempty"` // Source span of the item (including the direction and the nulls order)
 }
 
 type SoqlOffsetAndLimitClause struct {
@@ -239,17 +310,79 @@ type SoqlForClause struct {
 	UpdateViewstat bool `json:"updateViewstat,omitempty"` // for update viewstat (set with Update)
 }
 
+type SoqlGroupingMode int
+
+const (
+	SoqlGroupingMode_Default                SoqlGroupingMode = iota // group by
+	SoqlGroupingMode_Rollup                                         // group by rollup
+	SoqlGroupingMode_Cube                                           // group by cube
+	soqlGroupingMode_EndOfConstDefinitions_                         // For UnmarshalJSON (internal use)
+)
+
+func (t SoqlGroupingMode) String() string {
+	switch t {
+	case SoqlGroupingMode_Default:
+		return "Default"
+	case SoqlGroupingMode_Rollup:
+		return "Rollup"
+	case SoqlGroupingMode_Cube:
+		return "Cube"
+	default:
+		return "Undefined"
+	}
+}
+
+type SoqlDataCategoryOperator int
+
+const (
+	SoqlDataCategoryOperator_At                     SoqlDataCategoryOperator = iota + 1 // at
+	SoqlDataCategoryOperator_Above                                                      // above
+	SoqlDataCategoryOperator_Below                                                      // below
+	SoqlDataCategoryOperator_AboveOrBelow                                               // above_or_below
+	soqlDataCategoryOperator_EndOfConstDefinitions_                                     // For UnmarshalJSON (internal use)
+)
+
+func (t SoqlDataCategoryOperator) String() string {
+	switch t {
+	case SoqlDataCategoryOperator_At:
+		return "At"
+	case SoqlDataCategoryOperator_Above:
+		return "Above"
+	case SoqlDataCategoryOperator_Below:
+		return "Below"
+	case SoqlDataCategoryOperator_AboveOrBelow:
+		return "AboveOrBelow"
+	default:
+		return "Undefined"
+	}
+}
+
+type SoqlDataCategoryFilter struct {
+	GroupName  string                   `json:"groupName,omitempty"`  // Data category group name
+	Operator   SoqlDataCategoryOperator `json:"operator,omitempty"`   // Filtering selector
+	Categories []string                 `json:"categories,omitempty"` // Data category names
+	Span       *SoqlSourceSpan          `json:"span,omitempty"`       // Source span of the filter
+}
+
+type SoqlWithClause struct {
+	SecurityEnforced bool                     `json:"securityEnforced,omitempty"` // with security_enforced
+	UserMode         bool                     `json:"userMode,omitempty"`         // with user_mode
+	SystemMode       bool                     `json:"systemMode,omitempty"`       // with system_mode
+	DataCategory     []SoqlDataCategoryFilter `json:"dataCategory,omitempty"`     // with data category; Filters are joined by 'and'
+}
+
 type SoqlViewGraphLeaf struct {
-	Name         string          `json:"name"`                // Name
-	ParentViewId int             `json:"parentViewId"`        // View id of parent object on object graph
-	QueryId      int             `json:"queryId"`             // Query unique id
-	Depth        int             `json:"depth"`               // Depth on object graph
-	QueryDepth   int             `json:"queryDepth"`          // Query depth
-	Many         bool            `json:"many,omitempty"`      // True if it is one-to-many relationship (subquery)
-	InnerJoin    bool            `json:"innerJoin,omitempty"` // Inner join to parent view id
-	NonResult    bool            `json:"nonResult,omitempty"` // True if it is subquery on conditions (where | having clause)
-	Object       *SoqlObjectInfo `json:"-"`                   // Object
-	Query        *SoqlQuery      `json:"-"`                   // Query
+	Name            string          `json:"name"`                      // Name
+	ParentViewId    int             `json:"parentViewId"`              // View id of parent object on object graph
+	QueryId         int             `json:"queryId"`                   // Query unique id
+	Depth           int             `json:"depth"`                     // Depth on object graph
+	QueryDepth      int             `json:"queryDepth"`                // Query depth
+	Many            bool            `json:"many,omitempty"`            // True if it is one-to-many relationship (subquery)
+	InnerJoin       bool            `json:"innerJoin,omitempty"`       // Inner join to parent view id
+	NonResult       bool            `json:"nonResult,omitempty"`       // True if it is subquery on conditions (where | having clause)
+	PolymorphicType string          `json:"polymorphicType,omitempty"` // Object type of the polymorphic relationship (`typeof` ... `when` branch)
+	Object          *SoqlObjectInfo `json:"-"`                         // Object
+	Query           *SoqlQuery      `json:"-"`                         // Query
 }
 
 type SoqlQueryGraphLeaf struct {
@@ -260,31 +393,36 @@ type SoqlQueryGraphLeaf struct {
 }
 
 type SoqlQueryMeta struct {
-	Version          string                     `json:"version,omitempty"`          // format version
-	Date             time.Time                  `json:"date,omitempty"`             // compiled datetime
-	ElapsedTime      time.Duration              `json:"elapsedTime,omitempty"`      // time taken to compile
-	Source           string                     `json:"source,omitempty"`           // source
-	MaxQueryDepth    int                        `json:"maxQueryDepth,omitempty"`    // max depth of query graph
-	MaxViewDepth     int                        `json:"maxViewDepth,omitempty"`     // max depth of object graph
-	NextQueryId      int                        `json:"nextQueryId,omitempty"`      // next query id (a number of queries)
-	NextViewId       int                        `json:"nextViewId,omitempty"`       // next view id (a number of views)
-	NextColumnId     int                        `json:"nextColumnId,omitempty"`     // next column id (a number of columns)
-	QueryGraph       map[int]SoqlQueryGraphLeaf `json:"queryGraph,omitempty"`       // query graph (child -> parent)
-	ViewGraph        map[int]SoqlViewGraphLeaf  `json:"viewGraph,omitempty"`        // object graph (child -> parent)
-	Functions        map[string]struct{}        `json:"functions,omitempty"`        // functions
-	Parameters       map[string]struct{}        `json:"parameters,omitempty"`       // parameters
-	DateTimeLiterals map[string]struct{}        `json:"dateTimeLiterals,omitempty"` // datetime literals
+	Version            string                     `json:"version,omitempty"`            // format version
+	Date               time.Time                  `json:"date,omitempty"`               // compiled datetime
+	ElapsedTime        time.Duration              `json:"elapsedTime,omitempty"`        // time taken to compile
+	Source             string                     `json:"source,omitempty"`             // source
+	MaxQueryDepth      int                        `json:"maxQueryDepth,omitempty"`      // max depth of query graph
+	MaxViewDepth       int                        `json:"maxViewDepth,omitempty"`       // max depth of object graph
+	NextQueryId        int                        `json:"nextQueryId,omitempty"`        // next query id (a number of queries)
+	NextViewId         int                        `json:"nextViewId,omitempty"`         // next view id (a number of views)
+	NextColumnId       int                        `json:"nextColumnId,omitempty"`       // next column id (a number of columns)
+	QueryGraph         map[int]SoqlQueryGraphLeaf `json:"queryGraph,omitempty"`         // query graph (child -> parent)
+	ViewGraph          map[int]SoqlViewGraphLeaf  `json:"viewGraph,omitempty"`          // object graph (child -> parent)
+	Functions          map[string]struct{}        `json:"functions,omitempty"`          // functions
+	Parameters         map[string]struct{}        `json:"parameters,omitempty"`         // parameters
+	DateTimeLiterals   map[string]struct{}        `json:"dateTimeLiterals,omitempty"`   // datetime literals
+	DataCategoryGroups map[string]struct{}        `json:"dataCategoryGroups,omitempty"` // data category groups
+	CurrencyCodes      map[string]struct{}        `json:"currencyCodes,omitempty"`      // ISO currency codes of the currency literals
 }
 
 type SoqlQuery struct {
 	Fields           []SoqlFieldInfo          `json:"fields,omitempty"`           // Select clause fields; possibly null
 	From             []SoqlObjectInfo         `json:"from,omitempty"`             // From clause objects; has at least one element
 	Where            []SoqlCondition          `json:"where,omitempty"`            // Where clause conditions; possibly null; Not used in the execution planning phase.
+	With             SoqlWithClause           `json:"with,omitempty"`             // With clause
 	GroupBy          []SoqlFieldInfo          `json:"groupBy,omitempty"`          // Group by clause fields; possibly null; Not used for "PerObjectQuery"
+	GroupingMode     SoqlGroupingMode         `json:"groupingMode,omitempty"`     // Group by clause grouping mode (rollup, cube); Not used for "PerObjectQuery"
 	Having           []SoqlCondition          `json:"having,omitempty"`           // Having clause conditions; possibly null; Not used for "PerObjectQuery"
 	OrderBy          []SoqlOrderByInfo        `json:"orderBy,omitempty"`          // Order by clause fields; possibly null
 	OffsetAndLimit   SoqlOffsetAndLimitClause `json:"offsetAndLimit,omitempty"`   // Offset and limit clause
 	For              SoqlForClause            `json:"for,omitempty"`              // For clause
+	AllRows          bool                     `json:"allRows,omitempty"`          // All rows clause; Deleted and archived records are included if true. It is propagated to the subqueries and "PerObjectQuery".
 	Parent           *SoqlQuery               `json:"-"`                          // Pointer to parent query; Not used for "PerObjectQuery"
 	IsAggregation    bool                     `json:"isAggregation,omitempty"`    // It is an aggregation result or not; Not used for "PerObjectQuery"
 	IsCorelated      bool                     `json:"isCorelated,omitempty"`      // Co-related query if true
@@ -292,3 +430,59 @@ type SoqlQuery struct {
 	QueryId          int                      `json:"queryId,omitempty"`          // Query unique id
 	Meta             *SoqlQueryMeta           `json:"meta,omitempty"`             // Meta information
 }
+
+type SoslSearchGroup int
+
+const (
+	SoslSearchGroup_AllFields              SoslSearchGroup = iota // in all fields (default)
+	SoslSearchGroup_NameFields                                    // in name fields
+	SoslSearchGroup_EmailFields                                   // in email fields
+	SoslSearchGroup_PhoneFields                                   // in phone fields
+	SoslSearchGroup_SidebarFields                                 // in sidebar fields
+	soslSearchGroup_EndOfConstDefinitions_                        // For UnmarshalJSON (internal use)
+)
+
+func (t SoslSearchGroup) String() string {
+	switch t {
+	case SoslSearchGroup_AllFields:
+		return "AllFields"
+	case SoslSearchGroup_NameFields:
+		return "NameFields"
+	case SoslSearchGroup_EmailFields:
+		return "EmailFields"
+	case SoslSearchGroup_PhoneFields:
+		return "PhoneFields"
+	case SoslSearchGroup_SidebarFields:
+		return "SidebarFields"
+	default:
+		return "Undefined"
+	}
+}
+
+type SoslWithClause struct {
+	SoqlWithClause               // with security_enforced | user_mode | system_mode, with data category
+	Division            string   `json:"division,omitempty"`            // with division = 'name'
+	Highlight           bool     `json:"highlight,omitempty"`           // with highlight
+	Snippet             bool     `json:"snippet,omitempty"`             // with snippet
+	SnippetTargetLength int64    `json:"snippetTargetLength,omitempty"` // with snippet (target_length = n); 0 represents the default length.
+	NoSpellCorrection   bool     `json:"noSpellCorrection,omitempty"`   // with spell_correction = false
+	Metadata            string   `json:"metadata,omitempty"`            // with metadata = 'LABELS'
+	PricebookId         string   `json:"pricebookId,omitempty"`         // with pricebookid = 'id'
+	Network             []string `json:"network,omitempty"`             // with network = 'id' | with network in ('id', ...)
+}
+
+type SoslUpdateClause struct {
+	Tracking bool `json:"tracking,omitempty"` // update tracking
+	Viewstat bool `json:"viewstat,omitempty"` // update viewstat
+}
+
+type SoslQuery struct {
+	SearchQuery    string           `json:"searchQuery"`              // Find clause search query; The text in the braces is kept as is (including escapes).
+	SearchGroup    SoslSearchGroup  `json:"searchGroup,omitempty"`    // In clause search group
+	Returning      []SoqlQuery      `json:"returning,omitempty"`      // Returning clause objects; Each object is normalized as an independent query.
+	With           SoslWithClause   `json:"with,omitempty"`           // With clause
+	Limit          int64            `json:"limit,omitempty"`          // limit; 0 represents not limited.
+	LimitParamName string           `json:"limitParamName,omitempty"` // limit for parameterized query; If set, it has precedence over the value
+	Update         SoslUpdateClause `json:"update,omitempty"`         // Update clause
+	Meta           *SoqlQueryMeta   `json:"meta,omitempty"`           // Meta information; Maps are the union of the returning queries.
+}
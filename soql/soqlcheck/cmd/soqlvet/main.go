@@ -0,0 +1,15 @@
+// Command soqlvet checks the SOQL string constants in the Go source.
+//
+// Usage:
+//
+//	soqlvet [-funcs=importpath.Func,importpath.Type.Method:1,...] package ...
+package main
+
+import (
+	"github.com/shellyln/go-open-soql-parser/soql/soqlcheck"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(soqlcheck.Analyzer)
+}
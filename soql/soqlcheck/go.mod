@@ -0,0 +1,17 @@
+module github.com/shellyln/go-open-soql-parser/soql/soqlcheck
+
+go 1.22.0
+
+require (
+	github.com/shellyln/go-open-soql-parser v0.0.9
+	golang.org/x/tools v0.26.0
+)
+
+require (
+	github.com/shellyln/go-nameutil v0.0.2 // indirect
+	github.com/shellyln/takenoco v0.0.13 // indirect
+	golang.org/x/mod v0.21.0 // indirect
+	golang.org/x/sync v0.8.0 // indirect
+)
+
+replace github.com/shellyln/go-open-soql-parser => ../..
@@ -0,0 +1,177 @@
+// Analyzer that checks the SOQL (and SOSL) string constants passed to the configured functions.
+package soqlcheck
+
+import (
+	"errors"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/shellyln/go-open-soql-parser/soql/parser"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const Doc = `check SOQL string constants passed to the query functions
+
+The soqlcheck analyzer parses and normalizes the string constants that are
+passed to the functions listed in the -funcs flag, and reports the errors
+at the position in the Go source.`
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "soqlcheck",
+	Doc:      Doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// Comma separated list of the functions.
+// Each item is "importpath.Func" or "importpath.Type.Method",
+// optionally followed by ":n" (0-based index of the query argument; default is 0).
+var funcs = "github.com/shellyln/go-open-soql-parser/soql/parser.Parse," +
+	"github.com/shellyln/go-open-soql-parser/soql/parser.ParseWithOptions," +
+	"github.com/shellyln/go-open-soql-parser/soql/parser.ParseSosl," +
+	"github.com/shellyln/go-open-soql-parser/soql/parser.ParseSoslWithOptions"
+
+func init() {
+	Analyzer.Flags.StringVar(&funcs, "funcs", funcs, "comma separated list of the query functions (importpath.Func or importpath.Type.Method, optionally followed by :argIndex)")
+}
+
+func parseFuncs(s string) map[string]int {
+	z := make(map[string]int)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		index := 0
+		if i := strings.LastIndexByte(item, ':'); i >= 0 {
+			if n, err := strconv.Atoi(item[i+1:]); err == nil && n >= 0 {
+				index = n
+				item = item[:i]
+			}
+		}
+		z[item] = index
+	}
+	return z
+}
+
+func funcKey(fn *types.Func) string {
+	if fn.Pkg() == nil {
+		return ""
+	}
+	sig, ok := fn.Type().(*types.Signature)
+	if !ok || sig.Recv() == nil {
+		return fn.Pkg().Path() + "." + fn.Name()
+	}
+	recv := sig.Recv().Type()
+	if p, ok := recv.(*types.Pointer); ok {
+		recv = p.Elem()
+	}
+	if named, ok := recv.(*types.Named); ok {
+		return fn.Pkg().Path() + "." + named.Obj().Name() + "." + fn.Name()
+	}
+	return ""
+}
+
+// Map the byte offset in the value of the string literal to the position in the Go source.
+func literalPos(lit *ast.BasicLit, offset int) token.Pos {
+	if lit.Value == "" {
+		return lit.Pos()
+	}
+	if lit.Value[0] == '`' {
+		return lit.Pos() + 1 + token.Pos(offset)
+	}
+
+	body := lit.Value[1 : len(lit.Value)-1]
+	src, dest := 0, 0
+	for src < len(body) && dest < offset {
+		v, multibyte, tail, err := strconv.UnquoteChar(body[src:], '"')
+		if err != nil {
+			return lit.Pos()
+		}
+		if multibyte {
+			dest += utf8.RuneLen(v)
+		} else {
+			dest++
+		}
+		src = len(body) - len(tail)
+	}
+	return lit.Pos() + 1 + token.Pos(src)
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+func isSosl(query string) bool {
+	s := strings.TrimSpace(query)
+	return len(s) >= 4 && strings.EqualFold(s[:4], "find")
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	targets := parseFuncs(funcs)
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	nodeFilter := []ast.Node{
+		(*ast.CallExpr)(nil),
+	}
+
+	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		call := n.(*ast.CallExpr)
+
+		fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+		if !ok {
+			return
+		}
+		index, ok := targets[funcKey(fn)]
+		if !ok || index >= len(call.Args) {
+			return
+		}
+
+		arg := call.Args[index]
+		tv, ok := pass.TypesInfo.Types[arg]
+		if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+			return
+		}
+		query := constant.StringVal(tv.Value)
+
+		var err error
+		if isSosl(query) {
+			_, err = parser.ParseSosl(query)
+		} else {
+			_, err = parser.Parse(query)
+		}
+		if err == nil {
+			return
+		}
+
+		diag := analysis.Diagnostic{
+			Pos:     arg.Pos(),
+			End:     arg.End(),
+			Message: firstLine(err.Error()),
+		}
+
+		var perr *parser.ParseError
+		if errors.As(err, &perr) {
+			diag.Message = firstLine(perr.Message)
+			if lit, ok := ast.Unparen(arg).(*ast.BasicLit); ok {
+				diag.Pos = literalPos(lit, perr.Offset)
+				diag.End = literalPos(lit, perr.EndOffset)
+			}
+		}
+
+		pass.Report(diag)
+	})
+
+	return nil, nil
+}
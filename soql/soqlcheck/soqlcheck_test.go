@@ -0,0 +1,15 @@
+package soqlcheck_test
+
+import (
+	"testing"
+
+	"github.com/shellyln/go-open-soql-parser/soql/soqlcheck"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	if err := soqlcheck.Analyzer.Flags.Set("funcs", "client.Client.Query,client.Search:1"); err != nil {
+		t.Fatal(err)
+	}
+	analysistest.Run(t, analysistest.TestData(), soqlcheck.Analyzer, "a")
+}
@@ -0,0 +1,19 @@
+package a
+
+import "client"
+
+const fields = "Id, Name"
+
+func f(c *client.Client, q string) {
+	c.Query("SELECT Id FROM Account")
+	c.Query("SELECT FROM Account")        // want `Unexpected token aheads near by the select clause \(field list\)`
+	c.Query("SELECT \"x\", FROM Account") // want `Unexpected token aheads near by the select clause \(field list\)`
+	c.Query(`SELECT Id
+	FROM Account
+	WHERE Name = `) // want `Unexpected token aheads`
+	c.Query("SELECT " + fields + " FROM Account WHERE")     // want `Unexpected token aheads`
+	c.Query("SELECT Name FROM Account GROUP BY Name, name") // want `Duplicate field found in Group by clause`
+	c.Query(q)
+	client.Search(c, "FIND {a} RETURNING Account(Name), Account") // want `Duplicate object found in the returning clause: Account`
+	client.Search(c, "FIND {a} RETURNING Account(Name)")
+}
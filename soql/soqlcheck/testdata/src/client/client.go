@@ -0,0 +1,7 @@
+package client
+
+type Client struct{}
+
+func (c *Client) Query(q string) error { return nil }
+
+func Search(c *Client, q string) error { return nil }
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"syscall/js"
 
+	"github.com/shellyln/go-open-soql-parser/soql/cst"
 	"github.com/shellyln/go-open-soql-parser/soql/parser"
 )
 
@@ -44,6 +45,20 @@ func parseSoql(this js.Value, args []js.Value) interface{} {
 	return js.ValueOf(buf.String())
 }
 
+func tokenizeSoql(this js.Value, args []js.Value) interface{} {
+	src := ""
+	if 0 < len(args) {
+		src = args[0].String()
+	}
+
+	jsonStr, err := json.Marshal(cst.Tokenize(src))
+	if err != nil {
+		return js.ValueOf(err.Error())
+	}
+
+	return js.ValueOf(string(jsonStr))
+}
+
 func getVersion(this js.Value, args []js.Value) interface{} {
 	return js.ValueOf(Version)
 }
@@ -52,6 +67,7 @@ func main() {
 	println("Go WebAssembly Initialized")
 
 	js.Global().Set("parseSoql", js.FuncOf(parseSoql))
+	js.Global().Set("tokenizeSoql", js.FuncOf(tokenizeSoql))
 	js.Global().Set("getVersion", js.FuncOf(getVersion))
 
 	select {}